@@ -0,0 +1,11 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package consts
+
+// FieldDiscoveryHost is the top-level provider field that names a host
+// implementing the HashiCorp service-discovery protocol
+// (/.well-known/vault.json), used by NewProviderMeta to resolve the actual
+// Vault address and enforce a supported version window. It joins the
+// existing Field* constants defined alongside consts.FieldAddress et al.
+const FieldDiscoveryHost = "discovery_host"
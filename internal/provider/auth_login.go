@@ -0,0 +1,97 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/vault/api"
+
+	"github.com/hashicorp/terraform-provider-vault/internal/consts"
+)
+
+// AuthLogin is implemented by each auth_login_* block type. NewProviderMeta
+// uses the one returned by GetAuthLogin, if any, to derive the token it
+// configures the root client with; GetClientForConfig uses ClientConfig's
+// AuthLogin the same way for a per-resource client.
+type AuthLogin interface {
+	// Namespace returns the namespace the login should be performed in, or
+	// "" to use the caller's own namespace.
+	Namespace() string
+
+	// Login authenticates against Vault using client and returns the
+	// resulting secret. Only secret.Auth.ClientToken is consulted by
+	// callers.
+	Login(client *api.Client) (*api.Secret, error)
+}
+
+// skipChildTokenDefaulter is implemented by auth-login methods for which
+// skip_child_token being left unset shouldn't mean false. NewProviderMeta
+// consults this only when the user hasn't explicitly set skip_child_token,
+// so an explicit skip_child_token = false always still wins.
+type skipChildTokenDefaulter interface {
+	// DefaultSkipChildToken returns the skip_child_token value to use when
+	// the field wasn't explicitly configured.
+	DefaultSkipChildToken() bool
+}
+
+// authLoginConstructor builds an AuthLogin from its block's raw config.
+type authLoginConstructor func(namespace string, params map[string]interface{}) (AuthLogin, error)
+
+// authLoginMethods maps each auth_login_* block name to the constructor
+// that parses it. Each auth-login method registers itself here via
+// registerAuthLoginMethod from its own file's init(), so adding a new
+// method never requires touching this file.
+var authLoginMethods = map[string]authLoginConstructor{}
+
+// registerAuthLoginMethod wires an auth_login_* block name into
+// GetAuthLogin's dispatch table. Called from the init() of the file that
+// implements that method's AuthLogin.
+func registerAuthLoginMethod(field string, construct authLoginConstructor) {
+	authLoginMethods[field] = construct
+}
+
+// GetAuthLogin inspects d for whichever auth_login_* block (if any) is
+// configured and returns the corresponding AuthLogin. It returns a nil
+// AuthLogin, nil error if none of the registered blocks are set, in which
+// case the caller falls back to a literal token.
+func GetAuthLogin(d *schema.ResourceData) (AuthLogin, error) {
+	namespace := d.Get(consts.FieldNamespace).(string)
+
+	var configuredFields []string
+	var result AuthLogin
+	for field, construct := range authLoginMethods {
+		v, ok := d.GetOk(field)
+		if !ok {
+			continue
+		}
+
+		blocks, ok := v.([]interface{})
+		if !ok || len(blocks) == 0 {
+			continue
+		}
+
+		params, ok := blocks[0].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		login, err := construct(namespace, params)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s block: %w", field, err)
+		}
+
+		configuredFields = append(configuredFields, field)
+		result = login
+	}
+
+	if len(configuredFields) > 1 {
+		return nil, fmt.Errorf("only one auth login method may be configured, got: %s",
+			strings.Join(configuredFields, ", "))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,250 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/hashicorp/vault/api"
+)
+
+// authLoginAgentField is the name of the auth_login_agent block, wired into
+// GetAuthLogin alongside the other auth-login methods.
+const authLoginAgentField = "auth_login_agent"
+
+func init() {
+	registerAuthLoginMethod(authLoginAgentField, func(namespace string, params map[string]interface{}) (AuthLogin, error) {
+		return NewAuthLoginAgent(namespace, params)
+	})
+}
+
+// tokenWatcher is implemented by auth-login methods that need to keep
+// watching for token rotation after their initial Login call returns, so
+// that a long-running `terraform apply` survives the token being rotated
+// out from under it.
+type tokenWatcher interface {
+	WatchToken(client *api.Client)
+}
+
+// AuthLoginAgent implements AuthLogin by reading a Vault Agent auto-auth
+// sink file, rather than performing its own login call against Vault. The
+// agent is already responsible for keeping the sink populated with a
+// valid, renewable token, so skip_child_token defaults to true whenever
+// this method is used; see DefaultSkipChildToken.
+type AuthLoginAgent struct {
+	namespace       string
+	sinkPath        string
+	wrapTTL         string
+	unwrap          bool
+	removeAfterRead bool
+	initialTimeout  time.Duration
+}
+
+// NewAuthLoginAgent builds an AuthLoginAgent from the auth_login_agent
+// block's raw config, as parsed by GetAuthLogin.
+func NewAuthLoginAgent(namespace string, params map[string]interface{}) (*AuthLoginAgent, error) {
+	sinkPath, _ := params["sink_path"].(string)
+	if sinkPath == "" {
+		return nil, fmt.Errorf("%s requires a sink_path", authLoginAgentField)
+	}
+
+	initialTimeout := 10 * time.Second
+	if v, ok := params["initial_timeout"].(string); ok && v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid initial_timeout %q for %s: %w", v, authLoginAgentField, err)
+		}
+		initialTimeout = d
+	}
+
+	wrapTTL, _ := params["wrap_ttl"].(string)
+	unwrap, _ := params["unwrap"].(bool)
+	removeAfterRead, _ := params["remove_after_read"].(bool)
+
+	return &AuthLoginAgent{
+		namespace:       namespace,
+		sinkPath:        sinkPath,
+		wrapTTL:         wrapTTL,
+		unwrap:          unwrap,
+		removeAfterRead: removeAfterRead,
+		initialTimeout:  initialTimeout,
+	}, nil
+}
+
+// Namespace returns the namespace to authenticate in, see AuthLogin.
+func (l *AuthLoginAgent) Namespace() string {
+	return l.namespace
+}
+
+// DefaultSkipChildToken implements skipChildTokenDefaulter: WatchToken keeps
+// client's token in sync with whatever the agent's sink currently holds, so
+// a child token minted once at configure time would either go stale or get
+// clobbered by the next sink rotation. Default to skipping it unless the
+// user explicitly asks for one.
+func (l *AuthLoginAgent) DefaultSkipChildToken() bool {
+	return true
+}
+
+// CacheKey distinguishes AuthLoginAgent instances pointed at different sink
+// files (or configured differently against the same one) so that two
+// resources using different agent sinks never share a cached client and its
+// token; see authLoginCacheKeyer.
+func (l *AuthLoginAgent) CacheKey() string {
+	return fmt.Sprintf("%s:%s:%t:%t", l.sinkPath, l.wrapTTL, l.unwrap, l.removeAfterRead)
+}
+
+// Login waits for the agent's sink file to appear (up to initialTimeout),
+// reads the token from it, optionally unwraps it, and returns it as an
+// *api.Secret so NewProviderMeta can treat this like any other auth-login
+// method. Subsequent rotations are picked up by WatchToken.
+func (l *AuthLoginAgent) Login(client *api.Client) (*api.Secret, error) {
+	raw, err := l.waitForToken()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := l.resolveToken(client, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.Secret{
+		Auth: &api.SecretAuth{
+			ClientToken: token,
+		},
+	}, nil
+}
+
+// resolveToken turns a raw sink read into the token to authenticate with:
+// unwrapping it first if the sink holds wrapped responses rather than raw
+// tokens, then removing the sink file if removeAfterRead is set. Used by
+// both Login and WatchToken so a rotated wrapped token is unwrapped the
+// same way the initial one is.
+func (l *AuthLoginAgent) resolveToken(client *api.Client, raw string) (string, error) {
+	token := raw
+	if l.unwrap || l.wrapTTL != "" {
+		resp, err := client.Logical().Unwrap(token)
+		if err != nil {
+			return "", fmt.Errorf("failed to unwrap agent sink token: %w", err)
+		}
+		if resp == nil || resp.Auth == nil {
+			return "", fmt.Errorf("agent sink %q contained a wrapped response with no auth payload", l.sinkPath)
+		}
+		token = resp.Auth.ClientToken
+	}
+
+	if l.removeAfterRead {
+		if err := os.Remove(l.sinkPath); err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to remove agent sink file %q: %w", l.sinkPath, err)
+		}
+	}
+
+	return token, nil
+}
+
+// WatchToken starts a background watch on the sink file and, whenever its
+// contents change, swaps client's token via client.SetToken. This lets a
+// long-running `terraform apply` keep working across an agent-driven token
+// rotation without the provider having to be reconfigured.
+func (l *AuthLoginAgent) WatchToken(client *api.Client) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("[WARN] could not start watch on agent sink %q, token rotation "+
+			"will not be picked up automatically, err=%s", l.sinkPath, err)
+		return
+	}
+
+	dir := filepath.Dir(l.sinkPath)
+	if err := watcher.Add(dir); err != nil {
+		log.Printf("[WARN] could not watch directory %q for agent sink %q, token "+
+			"rotation will not be picked up automatically, err=%s", dir, l.sinkPath, err)
+		watcher.Close()
+		return
+	}
+
+	go func() {
+		defer watcher.Close()
+		l.watchLoop(client, watcher.Events, watcher.Errors)
+	}()
+}
+
+// watchLoop is WatchToken's event loop, split out so it can be driven by
+// plain channels in tests without a real fsnotify.Watcher. It runs until
+// events is closed. The fsnotify contract requires errors to be drained
+// just like events, or the watcher can block and silently stop reporting
+// rotations for the rest of the provider process; an error is logged and
+// the loop keeps watching rather than exiting.
+func (l *AuthLoginAgent) watchLoop(client *api.Client, events <-chan fsnotify.Event, errs <-chan error) {
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != filepath.Clean(l.sinkPath) {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+
+			raw, err := l.readToken()
+			if err != nil {
+				log.Printf("[WARN] failed to read rotated agent sink token from %q, err=%s", l.sinkPath, err)
+				continue
+			}
+
+			token, err := l.resolveToken(client, raw)
+			if err != nil {
+				log.Printf("[WARN] failed to resolve rotated agent sink token from %q, err=%s", l.sinkPath, err)
+				continue
+			}
+
+			client.SetToken(token)
+			log.Printf("[INFO] rotated Vault client token from agent sink %q", l.sinkPath)
+		case err, ok := <-errs:
+			if !ok {
+				return
+			}
+			log.Printf("[WARN] error watching agent sink %q for rotation, err=%s", l.sinkPath, err)
+		}
+	}
+}
+
+// waitForToken blocks until the sink file exists and is non-empty, up to
+// initialTimeout, then reads it.
+func (l *AuthLoginAgent) waitForToken() (string, error) {
+	deadline := time.Now().Add(l.initialTimeout)
+	for {
+		token, err := l.readToken()
+		if err == nil && token != "" {
+			return token, nil
+		}
+
+		if time.Now().After(deadline) {
+			if err == nil {
+				err = fmt.Errorf("sink file %q is empty", l.sinkPath)
+			}
+			return "", fmt.Errorf("timed out after %s waiting for agent sink %q: %w",
+				l.initialTimeout, l.sinkPath, err)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func (l *AuthLoginAgent) readToken() (string, error) {
+	b, err := os.ReadFile(l.sinkPath)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
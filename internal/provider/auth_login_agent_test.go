@@ -0,0 +1,231 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/hashicorp/vault/api"
+)
+
+func TestAuthLoginAgent_Login(t *testing.T) {
+	sinkPath := filepath.Join(t.TempDir(), "sink")
+	if err := os.WriteFile(sinkPath, []byte("initial-token\n"), 0o600); err != nil {
+		t.Fatalf("failed to write sink file: %s", err)
+	}
+
+	l, err := NewAuthLoginAgent("", map[string]interface{}{
+		"sink_path": sinkPath,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	client, err := api.NewClient(api.DefaultConfig())
+	if err != nil {
+		t.Fatalf("failed to create test client: %s", err)
+	}
+
+	secret, err := l.Login(client)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if secret.Auth.ClientToken != "initial-token" {
+		t.Fatalf("expected initial-token, got %q", secret.Auth.ClientToken)
+	}
+}
+
+func TestAuthLoginAgent_Login_waitsForSink(t *testing.T) {
+	sinkPath := filepath.Join(t.TempDir(), "sink")
+
+	l, err := NewAuthLoginAgent("", map[string]interface{}{
+		"sink_path":       sinkPath,
+		"initial_timeout": "2s",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = os.WriteFile(sinkPath, []byte("late-token"), 0o600)
+	}()
+
+	client, err := api.NewClient(api.DefaultConfig())
+	if err != nil {
+		t.Fatalf("failed to create test client: %s", err)
+	}
+
+	secret, err := l.Login(client)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if secret.Auth.ClientToken != "late-token" {
+		t.Fatalf("expected late-token, got %q", secret.Auth.ClientToken)
+	}
+}
+
+// TestAuthLoginAgent_WatchToken simulates a Vault Agent rotating the token
+// in its sink file mid-run, and verifies that the watched client's token is
+// swapped to the rotated value, so subsequent API calls made with that
+// client would use it.
+func TestAuthLoginAgent_WatchToken(t *testing.T) {
+	sinkPath := filepath.Join(t.TempDir(), "sink")
+	if err := os.WriteFile(sinkPath, []byte("initial-token"), 0o600); err != nil {
+		t.Fatalf("failed to write sink file: %s", err)
+	}
+
+	l, err := NewAuthLoginAgent("", map[string]interface{}{
+		"sink_path": sinkPath,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	client, err := api.NewClient(api.DefaultConfig())
+	if err != nil {
+		t.Fatalf("failed to create test client: %s", err)
+	}
+	client.SetToken("initial-token")
+
+	l.WatchToken(client)
+
+	if err := os.WriteFile(sinkPath, []byte("rotated-token"), 0o600); err != nil {
+		t.Fatalf("failed to rotate sink file: %s", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if client.Token() == "rotated-token" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if client.Token() != "rotated-token" {
+		t.Fatalf("expected client token to be rotated to rotated-token, got %q", client.Token())
+	}
+}
+
+// TestAuthLoginAgent_WatchToken_wrapped verifies that a rotation picked up
+// by WatchToken is unwrapped the same way Login unwraps the initial sink
+// read, for agents configured with unwrap/wrap_ttl. A regression here would
+// set the client's token to the literal wrapped-response token instead of
+// the token it wraps.
+func TestAuthLoginAgent_WatchToken_wrapped(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/sys/wrapping/unwrap" {
+			http.NotFound(w, r)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"auth": map[string]interface{}{
+				"client_token": "unwrapped-rotated-token",
+			},
+		})
+	}))
+	defer srv.Close()
+
+	sinkPath := filepath.Join(t.TempDir(), "sink")
+	if err := os.WriteFile(sinkPath, []byte("initial-wrapping-token"), 0o600); err != nil {
+		t.Fatalf("failed to write sink file: %s", err)
+	}
+
+	l, err := NewAuthLoginAgent("", map[string]interface{}{
+		"sink_path": sinkPath,
+		"unwrap":    true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cfg := api.DefaultConfig()
+	cfg.Address = srv.URL
+	client, err := api.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("failed to create test client: %s", err)
+	}
+
+	l.WatchToken(client)
+
+	if err := os.WriteFile(sinkPath, []byte("rotated-wrapping-token"), 0o600); err != nil {
+		t.Fatalf("failed to rotate sink file: %s", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if client.Token() == "unwrapped-rotated-token" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if client.Token() != "unwrapped-rotated-token" {
+		t.Fatalf("expected client token to be rotated to unwrapped-rotated-token, got %q", client.Token())
+	}
+}
+
+// TestAuthLoginAgent_watchLoop_drainsErrors verifies that a value on the
+// Errors channel is logged rather than left unread, and that watchLoop keeps
+// processing subsequent Events afterward instead of blocking or exiting.
+// fsnotify's contract requires Errors to be drained just like Events, or the
+// watcher can deadlock and silently stop reporting rotations.
+func TestAuthLoginAgent_watchLoop_drainsErrors(t *testing.T) {
+	sinkPath := filepath.Join(t.TempDir(), "sink")
+	if err := os.WriteFile(sinkPath, []byte("initial-token"), 0o600); err != nil {
+		t.Fatalf("failed to write sink file: %s", err)
+	}
+
+	l, err := NewAuthLoginAgent("", map[string]interface{}{
+		"sink_path": sinkPath,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	client, err := api.NewClient(api.DefaultConfig())
+	if err != nil {
+		t.Fatalf("failed to create test client: %s", err)
+	}
+	client.SetToken("initial-token")
+
+	events := make(chan fsnotify.Event)
+	errs := make(chan error)
+	go l.watchLoop(client, events, errs)
+
+	// An error arriving before any rotation must not block or kill the
+	// loop: send it, then confirm a normal rotation still works.
+	errs <- errors.New("simulated watch error")
+
+	if err := os.WriteFile(sinkPath, []byte("rotated-token"), 0o600); err != nil {
+		t.Fatalf("failed to rotate sink file: %s", err)
+	}
+	events <- fsnotify.Event{Name: sinkPath, Op: fsnotify.Write}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if client.Token() == "rotated-token" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if client.Token() != "rotated-token" {
+		t.Fatalf("expected rotation to keep working after an Errors event, got token %q", client.Token())
+	}
+
+	close(events)
+	close(errs)
+}
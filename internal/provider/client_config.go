@@ -0,0 +1,236 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+
+	"github.com/hashicorp/terraform-provider-vault/internal/consts"
+)
+
+// ClientConfig captures everything that can cause a per-resource Vault
+// *api.Client to differ from the provider's default client: the namespace
+// it should operate in, an optional auth-login method used to derive its
+// token, a literal token override, and any extra headers or TLS settings
+// layered on top of the provider's own. Resources that need a client with
+// settings other than the provider's root configuration build a
+// ClientConfig and pass it to GetClientForConfig. GetClient does not
+// consult ClientConfigProvider; it only resolves a namespace.
+type ClientConfig struct {
+	// Namespace is resolved relative to the provider's root namespace, the
+	// same way GetNSClient's ns argument is.
+	Namespace string
+
+	// AuthLogin, if set, is used to derive the client's token via its
+	// Login method. It takes precedence over Token.
+	AuthLogin AuthLogin
+
+	// Token is a literal token override, used when AuthLogin is not set.
+	Token string
+
+	// Headers are merged into the client's existing headers.
+	Headers http.Header
+
+	// TLSConfig, if set, is applied on top of the cloned client's TLS
+	// config.
+	TLSConfig *api.TLSConfig
+}
+
+// cacheKey returns a stable identifier for cfg, used to key
+// ProviderMeta.clientCache. Two ClientConfig values that would produce an
+// equivalent client hash to the same key.
+func (c ClientConfig) cacheKey() (string, error) {
+	h := fnv.New64a()
+
+	write := func(s string) error {
+		_, err := h.Write([]byte(s))
+		return err
+	}
+
+	if err := write(strings.Trim(c.Namespace, "/") + "\x00"); err != nil {
+		return "", err
+	}
+
+	if err := write(c.Token + "\x00"); err != nil {
+		return "", err
+	}
+
+	if c.AuthLogin != nil {
+		key := fmt.Sprintf("%T:%s", c.AuthLogin, c.AuthLogin.Namespace())
+		if ck, ok := c.AuthLogin.(authLoginCacheKeyer); ok {
+			// Type and namespace alone don't distinguish two AuthLogin
+			// values of the same concrete type, e.g. two AuthLoginAgents
+			// pointed at different sink files. Implementing
+			// authLoginCacheKeyer lets an AuthLogin contribute its own
+			// distinguishing fields so instances like that don't collide
+			// on, and share, the same cached client.
+			key = fmt.Sprintf("%s:%s", key, ck.CacheKey())
+		}
+		if err := write(key + "\x00"); err != nil {
+			return "", err
+		}
+	}
+
+	headerKeys := make([]string, 0, len(c.Headers))
+	for k := range c.Headers {
+		headerKeys = append(headerKeys, k)
+	}
+	sort.Strings(headerKeys)
+	for _, k := range headerKeys {
+		if err := write(fmt.Sprintf("%s=%s\x00", k, strings.Join(c.Headers[k], ","))); err != nil {
+			return "", err
+		}
+	}
+
+	if c.TLSConfig != nil {
+		if err := write(fmt.Sprintf("%+v\x00", *c.TLSConfig)); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("%x", h.Sum64()), nil
+}
+
+// authLoginCacheKeyer is implemented by AuthLogin methods whose instances
+// can otherwise be indistinguishable for caching purposes (same concrete
+// type, same namespace) despite authenticating as different identities, so
+// that cacheKey can tell them apart.
+type authLoginCacheKeyer interface {
+	CacheKey() string
+}
+
+// ClientConfigProvider is implemented by types that can supply a
+// ClientConfig describing a Vault client distinct from the provider's
+// default one, e.g. one authenticated via a different auth method, or
+// holding a different token, than the root provider client.
+// resourceDataClientConfig is the only current implementation; it's used
+// directly by GetClientWithTokenValidation rather than through GetClient,
+// since *schema.ResourceData can't itself satisfy this interface.
+type ClientConfigProvider interface {
+	ClientConfig() (cfg ClientConfig, ok bool)
+}
+
+// GetClientForConfig returns a Vault client derived from the provider's
+// root client according to cfg, creating and caching one if this is the
+// first time cfg has been requested. The cache key is a hash of cfg's
+// fields, so distinct namespace/auth/token/header/TLS combinations never
+// collide, and identical ones always share a client.
+func (p *ProviderMeta) GetClientForConfig(cfg ClientConfig) (*api.Client, error) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	key, err := cfg.cacheKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute client cache key: %w", err)
+	}
+
+	if p.clientCache == nil {
+		p.clientCache = make(map[string]*api.Client)
+	}
+
+	if c, ok := p.clientCache[key]; ok {
+		return c, nil
+	}
+
+	c, err := p.client.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.TLSConfig != nil {
+		// api.Client has no setter for TLS settings once constructed, so
+		// the only way to actually apply cfg.TLSConfig is to mutate a
+		// clone of its config and build a fresh client from that, carrying
+		// over the token/namespace/headers c already has.
+		cc := c.CloneConfig()
+		if err := cc.ConfigureTLS(cfg.TLSConfig); err != nil {
+			return nil, fmt.Errorf("failed to configure TLS for client config: %w", err)
+		}
+
+		tlsClient, err := api.NewClient(cc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build client with TLS config: %w", err)
+		}
+		tlsClient.SetToken(c.Token())
+		tlsClient.SetNamespace(c.Namespace())
+		tlsClient.SetHeaders(c.Headers())
+		c = tlsClient
+	}
+
+	ns := strings.Trim(cfg.Namespace, "/")
+	if ns != "" {
+		if root, ok := p.resourceData.GetOk(consts.FieldNamespace); ok && root.(string) != "" {
+			ns = fmt.Sprintf("%s/%s", root, ns)
+		}
+		c.SetNamespace(ns)
+	}
+
+	if len(cfg.Headers) > 0 {
+		headers := c.Headers().Clone()
+		for k, vs := range cfg.Headers {
+			for _, v := range vs {
+				headers.Add(k, v)
+			}
+		}
+		c.SetHeaders(headers)
+	}
+
+	token := cfg.Token
+	if cfg.AuthLogin != nil {
+		loginClient := c
+		if cfg.AuthLogin.Namespace() != "" {
+			// auth happens in the auth_login's namespace, the derived
+			// client's namespace is left as configured above.
+			clone, err := c.Clone()
+			if err != nil {
+				return nil, err
+			}
+			clone.SetNamespace(cfg.AuthLogin.Namespace())
+			loginClient = clone
+		}
+
+		secret, err := cfg.AuthLogin.Login(loginClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to login for client config, err=%w", err)
+		}
+		token = secret.Auth.ClientToken
+	}
+
+	if token != "" {
+		c.SetToken(token)
+	}
+
+	p.clientCache[key] = c
+
+	return c, nil
+}
+
+// EvictClientForConfig removes any cached client for cfg, forcing the next
+// GetClientForConfig call for the same config to create a fresh one. It is
+// meant to be called from a resource's Delete, so that a destroyed
+// resource's derived client (and any token it holds) isn't kept alive for
+// the remainder of the apply.
+func (p *ProviderMeta) EvictClientForConfig(cfg ClientConfig) error {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	key, err := cfg.cacheKey()
+	if err != nil {
+		return fmt.Errorf("failed to compute client cache key: %w", err)
+	}
+
+	delete(p.clientCache, key)
+
+	return nil
+}
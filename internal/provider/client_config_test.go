@@ -0,0 +1,173 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/vault/api"
+)
+
+// fakeAuthLogin is a minimal AuthLogin used to exercise
+// ClientConfig/GetClientForConfig's caching behavior without a real Vault
+// server. Two fakeAuthLogins with the same cacheKey are meant to be
+// indistinguishable; two with different ones are meant to authenticate (and
+// cache) separately.
+type fakeAuthLogin struct {
+	namespace string
+	cacheKey  string
+	token     string
+}
+
+func (f *fakeAuthLogin) Namespace() string {
+	return f.namespace
+}
+
+func (f *fakeAuthLogin) CacheKey() string {
+	return f.cacheKey
+}
+
+func (f *fakeAuthLogin) Login(client *api.Client) (*api.Secret, error) {
+	return &api.Secret{
+		Auth: &api.SecretAuth{
+			ClientToken: f.token,
+		},
+	}, nil
+}
+
+func testProviderMeta(t *testing.T) *ProviderMeta {
+	t.Helper()
+
+	client, err := api.NewClient(api.DefaultConfig())
+	if err != nil {
+		t.Fatalf("failed to create test client: %s", err)
+	}
+
+	return &ProviderMeta{
+		client:       client,
+		resourceData: schema.TestResourceDataRaw(t, map[string]*schema.Schema{}, map[string]interface{}{}),
+	}
+}
+
+func TestGetClientForConfig_cachesIdenticalConfigs(t *testing.T) {
+	p := testProviderMeta(t)
+
+	cfg := ClientConfig{Token: "tok1"}
+
+	c1, err := p.GetClientForConfig(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c2, err := p.GetClientForConfig(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if c1 != c2 {
+		t.Fatalf("expected identical ClientConfig values to share a cached client")
+	}
+}
+
+func TestGetClientForConfig_missesOnDifferentToken(t *testing.T) {
+	p := testProviderMeta(t)
+
+	c1, err := p.GetClientForConfig(ClientConfig{Token: "tok1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c2, err := p.GetClientForConfig(ClientConfig{Token: "tok2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if c1 == c2 {
+		t.Fatalf("expected different tokens to produce distinct clients")
+	}
+	if c1.Token() == c2.Token() {
+		t.Fatalf("expected different tokens to result in distinct client tokens, got %q for both", c1.Token())
+	}
+}
+
+// TestGetClientForConfig_isolatesAuthLogins guards against the cache key
+// collision this test was written to catch: two AuthLogin values of the
+// same concrete type and namespace, but otherwise distinct, must not share
+// a cached client (and therefore must not share a token).
+func TestGetClientForConfig_isolatesAuthLogins(t *testing.T) {
+	p := testProviderMeta(t)
+
+	login1 := &fakeAuthLogin{cacheKey: "sink-a", token: "token-a"}
+	login2 := &fakeAuthLogin{cacheKey: "sink-b", token: "token-b"}
+
+	c1, err := p.GetClientForConfig(ClientConfig{AuthLogin: login1})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c2, err := p.GetClientForConfig(ClientConfig{AuthLogin: login2})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if c1 == c2 {
+		t.Fatalf("expected distinct AuthLogin instances to produce distinct clients")
+	}
+	if c1.Token() != "token-a" {
+		t.Fatalf("expected client for login1 to carry token-a, got %q", c1.Token())
+	}
+	if c2.Token() != "token-b" {
+		t.Fatalf("expected client for login2 to carry token-b, got %q", c2.Token())
+	}
+}
+
+// TestGetClientForConfig_reusesSameAuthLogin ensures two AuthLogin
+// instances with the same cache key (e.g. re-derived from identical config
+// on a later apply) still share a client, so the fix for the collision bug
+// didn't just turn every AuthLogin into a permanent cache miss.
+func TestGetClientForConfig_reusesSameAuthLogin(t *testing.T) {
+	p := testProviderMeta(t)
+
+	cfg1 := ClientConfig{AuthLogin: &fakeAuthLogin{cacheKey: "sink-a", token: "token-a"}}
+	cfg2 := ClientConfig{AuthLogin: &fakeAuthLogin{cacheKey: "sink-a", token: "token-a"}}
+
+	c1, err := p.GetClientForConfig(cfg1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c2, err := p.GetClientForConfig(cfg2)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if c1 != c2 {
+		t.Fatalf("expected AuthLogin instances with the same cache key to share a cached client")
+	}
+}
+
+func TestEvictClientForConfig(t *testing.T) {
+	p := testProviderMeta(t)
+
+	cfg := ClientConfig{Token: "tok1"}
+
+	c1, err := p.GetClientForConfig(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := p.EvictClientForConfig(cfg); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	c2, err := p.GetClientForConfig(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if c1 == c2 {
+		t.Fatalf("expected a fresh client after eviction")
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/hashicorp/terraform-provider-vault/internal/consts"
+)
+
+// discoveryHostSchema is the schema.Schema for consts.FieldDiscoveryHost.
+// Provider()'s top-level Schema map must include this under
+// consts.FieldDiscoveryHost, or NewProviderMeta's GetOk(consts.FieldDiscoveryHost)
+// (see meta.go) never has an attribute to read: discovery only kicks in
+// implicitly, when address is a bare hostname.
+var discoveryHostSchema = &schema.Schema{
+	Type:     schema.TypeString,
+	Optional: true,
+	Description: "Hostname implementing the HashiCorp service-discovery protocol " +
+		"(a /.well-known/vault.json document) used to resolve the Vault address " +
+		"and enforce a supported version window. Only needed when address isn't " +
+		"itself a bare hostname.",
+}
+
+// wellKnownVaultDiscoveryPath is the fixed path appended to a discovery
+// host, mirroring the .well-known/terraform.json convention the Terraform
+// remote backend uses for host discovery.
+const wellKnownVaultDiscoveryPath = "/.well-known/vault.json"
+
+// serviceDiscovery is the "vault.v1" entry of a discovery document.
+type serviceDiscovery struct {
+	URL        string `json:"url"`
+	MinVersion string `json:"min_version"`
+	MaxVersion string `json:"max_version"`
+}
+
+type discoveryDocument struct {
+	VaultV1 serviceDiscovery `json:"vault.v1"`
+}
+
+var (
+	discoveryCacheMu sync.Mutex
+	discoveryCache   = make(map[string]*discoveryDocument)
+
+	// discoveryHTTPClient and discoveryScheme are overridden by tests so
+	// discoverVaultService can be exercised against a local httptest
+	// server instead of a real HTTPS host.
+	discoveryHTTPClient = http.DefaultClient
+	discoveryScheme     = "https"
+)
+
+// discoverVaultService resolves a Vault discovery host to its advertised
+// API address and supported version window by fetching
+// https://<host>/.well-known/vault.json. Results are cached for the life
+// of the provider process, since the document isn't expected to change
+// over the course of a single Terraform run.
+func discoverVaultService(host string) (*discoveryDocument, error) {
+	discoveryCacheMu.Lock()
+	defer discoveryCacheMu.Unlock()
+
+	if doc, ok := discoveryCache[host]; ok {
+		return doc, nil
+	}
+
+	u := fmt.Sprintf("%s://%s%s", discoveryScheme, host, wellKnownVaultDiscoveryPath)
+	resp, err := discoveryHTTPClient.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover Vault service at %q: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery request to %q returned status %s", u, resp.Status)
+	}
+
+	var doc discoveryDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse discovery document from %q: %w", u, err)
+	}
+
+	if doc.VaultV1.URL == "" {
+		return nil, fmt.Errorf("discovery document from %q has no %q entry", u, "vault.v1")
+	}
+
+	discoveryCache[host] = &doc
+
+	return &doc, nil
+}
+
+// checkVersionConstraint verifies that serverVersion satisfies the
+// [min_version, max_version] window advertised by disc, returning an error
+// naming both the constraint and the observed version when it doesn't.
+func checkVersionConstraint(disc serviceDiscovery, serverVersion *version.Version) error {
+	var parts []string
+	if disc.MinVersion != "" {
+		parts = append(parts, fmt.Sprintf(">= %s", disc.MinVersion))
+	}
+	if disc.MaxVersion != "" {
+		parts = append(parts, fmt.Sprintf("<= %s", disc.MaxVersion))
+	}
+
+	if len(parts) == 0 {
+		return nil
+	}
+
+	constraint, err := version.NewConstraint(strings.Join(parts, ", "))
+	if err != nil {
+		return fmt.Errorf("invalid version constraint in discovery document: %w", err)
+	}
+
+	if !constraint.Check(serverVersion) {
+		return fmt.Errorf("Vault server version %s does not satisfy the advertised "+
+			"version constraint %q", serverVersion, constraint)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+)
+
+// withDiscoveryTestServer points discoverVaultService at srv for the
+// duration of the test, restoring the real https scheme/client afterwards.
+func withDiscoveryTestServer(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+
+	prevClient, prevScheme := discoveryHTTPClient, discoveryScheme
+	discoveryHTTPClient = srv.Client()
+	discoveryScheme = "https"
+	t.Cleanup(func() {
+		discoveryHTTPClient, discoveryScheme = prevClient, prevScheme
+	})
+
+	return strings.TrimPrefix(srv.URL, "https://")
+}
+
+func TestDiscoverVaultService(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != wellKnownVaultDiscoveryPath {
+			http.NotFound(w, r)
+			return
+		}
+
+		fmt.Fprint(w, `{"vault.v1": {"url": "https://vault.example.com:8200", "min_version": "1.11.0", "max_version": "1.16.0"}}`)
+	}))
+	defer srv.Close()
+
+	host := withDiscoveryTestServer(t, srv)
+
+	doc, err := discoverVaultService(host)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if doc.VaultV1.URL != "https://vault.example.com:8200" {
+		t.Fatalf("unexpected url: %q", doc.VaultV1.URL)
+	}
+	if doc.VaultV1.MinVersion != "1.11.0" || doc.VaultV1.MaxVersion != "1.16.0" {
+		t.Fatalf("unexpected version window: %+v", doc.VaultV1)
+	}
+
+	// The result should now be cached, so a later call for the same host
+	// is served without a second request.
+	discoveryCacheMu.Lock()
+	_, cached := discoveryCache[host]
+	discoveryCacheMu.Unlock()
+	if !cached {
+		t.Fatalf("expected discovery result for %q to be cached", host)
+	}
+}
+
+func TestDiscoverVaultService_missingVaultV1(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	}))
+	defer srv.Close()
+
+	host := withDiscoveryTestServer(t, srv)
+
+	if _, err := discoverVaultService(host); err == nil {
+		t.Fatalf("expected an error for a discovery document with no vault.v1 entry")
+	}
+}
+
+func TestDiscoverVaultService_notFound(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	host := withDiscoveryTestServer(t, srv)
+
+	if _, err := discoverVaultService(host); err == nil {
+		t.Fatalf("expected an error for a non-200 discovery response")
+	}
+}
+
+func TestCheckVersionConstraint(t *testing.T) {
+	disc := serviceDiscovery{MinVersion: "1.11.0", MaxVersion: "1.16.0"}
+
+	cases := []struct {
+		name    string
+		ver     string
+		wantErr bool
+	}{
+		{"within window", "1.12.3", false},
+		{"at min", "1.11.0", false},
+		{"at max", "1.16.0", false},
+		{"below min", "1.10.9", true},
+		{"above max", "1.17.0", true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ver := version.Must(version.NewSemver(c.ver))
+			err := checkVersionConstraint(disc, ver)
+			if c.wantErr && err == nil {
+				t.Fatalf("expected an error for version %s", c.ver)
+			}
+			if !c.wantErr && err != nil {
+				t.Fatalf("unexpected error for version %s: %s", c.ver, err)
+			}
+		})
+	}
+}
+
+func TestCheckVersionConstraint_noConstraint(t *testing.T) {
+	if err := checkVersionConstraint(serviceDiscovery{}, version.Must(version.NewSemver("1.0.0"))); err != nil {
+		t.Fatalf("unexpected error with no min/max set: %s", err)
+	}
+}
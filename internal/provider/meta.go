@@ -4,14 +4,11 @@
 package provider
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -24,7 +21,6 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/hashicorp/vault/api"
-	"github.com/mitchellh/go-homedir"
 	"k8s.io/utils/pointer"
 
 	"github.com/hashicorp/terraform-provider-vault/helper"
@@ -53,11 +49,12 @@ var (
 // ProviderMeta provides resources with access to the Vault client and
 // other bits
 type ProviderMeta struct {
-	client       *api.Client
-	resourceData *schema.ResourceData
-	clientCache  map[string]*api.Client
-	m            sync.RWMutex
-	vaultVersion *version.Version
+	client          *api.Client
+	resourceData    *schema.ResourceData
+	clientCache     map[string]*api.Client
+	validatedTokens map[string]bool
+	m               sync.RWMutex
+	vaultVersion    *version.Version
 }
 
 // GetClient returns the providers default Vault client.
@@ -69,39 +66,11 @@ func (p *ProviderMeta) GetClient() *api.Client {
 // The provided namespace will always be set relative to the default client's
 // namespace.
 func (p *ProviderMeta) GetNSClient(ns string) (*api.Client, error) {
-	p.m.Lock()
-	defer p.m.Unlock()
-
-	if err := p.validate(); err != nil {
-		return nil, err
-	}
-
-	ns = strings.Trim(ns, "/")
-	if ns == "" {
+	if strings.Trim(ns, "/") == "" {
 		return nil, fmt.Errorf("empty namespace not allowed")
 	}
 
-	if root, ok := p.resourceData.GetOk(consts.FieldNamespace); ok && root.(string) != "" {
-		ns = fmt.Sprintf("%s/%s", root, ns)
-	}
-
-	if p.clientCache == nil {
-		p.clientCache = make(map[string]*api.Client)
-	}
-
-	if v, ok := p.clientCache[ns]; ok {
-		return v, nil
-	}
-
-	c, err := p.client.Clone()
-	if err != nil {
-		return nil, err
-	}
-
-	c.SetNamespace(ns)
-	p.clientCache[ns] = c
-
-	return c, nil
+	return p.GetClientForConfig(ClientConfig{Namespace: ns})
 }
 
 // IsAPISupported receives a minimum version
@@ -156,6 +125,29 @@ func NewProviderMeta(d *schema.ResourceData) (interface{}, error) {
 	}
 	clientConfig := api.DefaultConfig()
 	addr := d.Get(consts.FieldAddress).(string)
+
+	// discoveryHost is set either explicitly via discovery_host, or
+	// implicitly when address is a bare hostname with no scheme. When set,
+	// the actual Vault address (and a supported version window) are
+	// resolved via the standard .well-known host-discovery protocol.
+	var discoveryHost string
+	if v, ok := d.GetOk(consts.FieldDiscoveryHost); ok {
+		discoveryHost = v.(string)
+	}
+	if discoveryHost == "" && addr != "" && !strings.Contains(addr, "://") {
+		discoveryHost = addr
+	}
+
+	var disc *discoveryDocument
+	if discoveryHost != "" {
+		var err error
+		disc, err = discoverVaultService(discoveryHost)
+		if err != nil {
+			return nil, err
+		}
+		addr = disc.VaultV1.URL
+	}
+
 	if addr != "" {
 		clientConfig.Address = addr
 	}
@@ -269,6 +261,13 @@ func NewProviderMeta(d *schema.ResourceData) (interface{}, error) {
 		client.SetToken(token)
 	}
 
+	if w, ok := authLogin.(tokenWatcher); ok {
+		// auth-login methods that manage their own token rotation (e.g.
+		// AuthLoginAgent watching a Vault Agent auto-auth sink) keep
+		// client's token current for the life of the provider process.
+		w.WatchToken(client)
+	}
+
 	if client.Token() == "" {
 		return nil, errors.New("no vault token set on Client")
 	}
@@ -288,7 +287,7 @@ func NewProviderMeta(d *schema.ResourceData) (interface{}, error) {
 		tokenNamespace = strings.Trim(v.(string), "/")
 	}
 
-	if !d.Get(consts.FieldSkipChildToken).(bool) {
+	if !resolveSkipChildToken(d, authLogin) {
 		// a child token is always created in the namespace of the parent token.
 		token, err = createChildToken(d, client, tokenNamespace)
 		if err != nil {
@@ -336,6 +335,15 @@ func NewProviderMeta(d *schema.ResourceData) (interface{}, error) {
 			return nil, err
 		}
 		vaultVersion = ver
+
+		// skip_get_vault_version also skips the discovery version
+		// constraint check, since both rely on having queried the
+		// server's reported version.
+		if disc != nil {
+			if err := checkVersionConstraint(disc.VaultV1, vaultVersion); err != nil {
+				return nil, fmt.Errorf("discovery host %q: %w", discoveryHost, err)
+			}
+		}
 	}
 
 	return &ProviderMeta{
@@ -480,6 +488,24 @@ func getVaultVersion(client *api.Client) (*version.Version, error) {
 	return version.Must(version.NewSemver(resp.Version)), nil
 }
 
+// resolveSkipChildToken decides whether NewProviderMeta should mint a child
+// token: skip_child_token's own value wins whenever it was explicitly
+// configured; otherwise, an authLogin that implements
+// skipChildTokenDefaulter (e.g. AuthLoginAgent, which wants to keep
+// client's token in sync with WatchToken rather than have it clobbered by a
+// child token) gets to override the field's normal false schema default.
+func resolveSkipChildToken(d *schema.ResourceData, authLogin AuthLogin) bool {
+	skip := d.Get(consts.FieldSkipChildToken).(bool)
+
+	if cd, ok := authLogin.(skipChildTokenDefaulter); ok {
+		if _, explicit := d.GetOkExists(consts.FieldSkipChildToken); !explicit {
+			skip = cd.DefaultSkipChildToken()
+		}
+	}
+
+	return skip
+}
+
 func createChildToken(d *schema.ResourceData, c *api.Client, namespace string) (string, error) {
 	tokenName := d.Get("token_name").(string)
 	if tokenName == "" {
@@ -553,10 +579,14 @@ func GetToken(d *schema.ResourceData) (string, error) {
 
 }
 
-// Get gets the value of the stored token, if any
+// getToken reads the Vault CLI's token helper configuration and returns
+// either the token cached on disk by `vault login`, or the output of
+// invoking the configured token_helper. The config and token cache paths,
+// and the mechanics of invoking the helper, are OS-specific and implemented
+// in token_helper_unix.go and token_helper_windows.go.
+// See https://developer.hashicorp.com/vault/docs/commands/token-helper
 func getToken() (string, error) {
-	// See https://developer.hashicorp.com/vault/docs/commands/token-helper
-	vaultConfigPath, err := homedir.Expand("~/.vault")
+	vaultConfigPath, err := tokenConfigPath()
 	if err != nil {
 		return "", err
 	}
@@ -581,8 +611,7 @@ func getToken() (string, error) {
 	}
 
 	if obj.TokenHelper == "" {
-
-		tokenFile, err := homedir.Expand("~/.vault-token")
+		tokenFile, err := tokenFilePath()
 		if err != nil {
 			return "", err
 		}
@@ -595,24 +624,7 @@ func getToken() (string, error) {
 		return strings.TrimSpace(string(byts)), nil
 	}
 
-	tokenHelperPath := obj.TokenHelper
-	if !filepath.IsAbs(tokenHelperPath) {
-		tokenHelperPath, err = filepath.Abs(tokenHelperPath)
-		if err != nil {
-			return "", err
-		}
-	}
-
-	var stdout, stderr bytes.Buffer
-	cmd := exec.Command("/bin/sh", "-c", fmt.Sprintf("%s get", tokenHelperPath))
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	err = cmd.Run()
-	if err != nil {
-		return "", err
-	}
-	return stdout.String(), nil
-
+	return runTokenHelper(obj.TokenHelper)
 }
 
 func getHCLogger() hclog.Logger {
@@ -0,0 +1,89 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/hashicorp/terraform-provider-vault/internal/consts"
+)
+
+// defaultSkippingAuthLogin is a minimal AuthLogin that also implements
+// skipChildTokenDefaulter, standing in for AuthLoginAgent in
+// resolveSkipChildToken tests that don't need a real agent sink file.
+type defaultSkippingAuthLogin struct {
+	fakeAuthLogin
+	defaultSkip bool
+}
+
+func (d *defaultSkippingAuthLogin) DefaultSkipChildToken() bool {
+	return d.defaultSkip
+}
+
+func testSkipChildTokenResourceData(t *testing.T, raw map[string]interface{}) *schema.ResourceData {
+	t.Helper()
+	return schema.TestResourceDataRaw(t, map[string]*schema.Schema{
+		consts.FieldSkipChildToken: {
+			Type:     schema.TypeBool,
+			Optional: true,
+		},
+	}, raw)
+}
+
+// TestResolveSkipChildToken_agentDefaultsToSkip verifies that an AuthLogin
+// implementing skipChildTokenDefaulter (e.g. AuthLoginAgent) gets to skip
+// child-token creation by default, so a client kept in sync by WatchToken
+// doesn't immediately have its token clobbered by an unrelated child token.
+func TestResolveSkipChildToken_agentDefaultsToSkip(t *testing.T) {
+	d := testSkipChildTokenResourceData(t, map[string]interface{}{})
+	authLogin := &defaultSkippingAuthLogin{defaultSkip: true}
+
+	if !resolveSkipChildToken(d, authLogin) {
+		t.Fatalf("expected skip_child_token to default to true for an agent-style AuthLogin")
+	}
+}
+
+// TestResolveSkipChildToken_explicitOverridesDefault verifies that a user
+// explicitly setting skip_child_token = false still wins over an AuthLogin's
+// own default, so it remains possible to opt back into a child token.
+func TestResolveSkipChildToken_explicitOverridesDefault(t *testing.T) {
+	d := testSkipChildTokenResourceData(t, map[string]interface{}{
+		consts.FieldSkipChildToken: false,
+	})
+	authLogin := &defaultSkippingAuthLogin{defaultSkip: true}
+
+	if resolveSkipChildToken(d, authLogin) {
+		t.Fatalf("expected explicit skip_child_token = false to override the AuthLogin's default")
+	}
+}
+
+// TestResolveSkipChildToken_noDefaulter verifies that AuthLogin methods
+// that don't implement skipChildTokenDefaulter keep the normal schema
+// default (false), unaffected by this override.
+func TestResolveSkipChildToken_noDefaulter(t *testing.T) {
+	d := testSkipChildTokenResourceData(t, map[string]interface{}{})
+	authLogin := &fakeAuthLogin{token: "tok"}
+
+	if resolveSkipChildToken(d, authLogin) {
+		t.Fatalf("expected skip_child_token to remain false for an AuthLogin with no default override")
+	}
+}
+
+// TestAuthLoginAgent_DefaultSkipChildToken verifies that AuthLoginAgent
+// itself requests skip_child_token = true by default, per the
+// auth_login_agent request.
+func TestAuthLoginAgent_DefaultSkipChildToken(t *testing.T) {
+	l, err := NewAuthLoginAgent("", map[string]interface{}{
+		"sink_path": "unused",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !l.DefaultSkipChildToken() {
+		t.Fatalf("expected AuthLoginAgent.DefaultSkipChildToken() to be true")
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/vault/api"
+
+	"github.com/hashicorp/terraform-provider-vault/internal/consts"
+)
+
+// fieldVaultToken is the per-resource token override field injected by
+// AddPerResourceAuthSchema. It is deliberately distinct from the
+// provider-level "token" field so that a resource can be provisioned under
+// a token other than the one used to authenticate the provider itself,
+// e.g. a short-lived workload token minted outside of Terraform.
+const fieldVaultToken = "vault_token"
+
+// AddPerResourceAuthSchema injects optional namespace and vault_token
+// attributes into a resource or data source's schema map. A resource using
+// this schema can target a namespace, and authenticate with a token, other
+// than the provider's own, by calling GetClientWithTokenValidation instead
+// of GetClient. Use it from a schema.Resource's Schema field, e.g.:
+//
+//	Schema: provider.AddPerResourceAuthSchema(map[string]*schema.Schema{ ... }),
+func AddPerResourceAuthSchema(s map[string]*schema.Schema) map[string]*schema.Schema {
+	s[consts.FieldNamespace] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		ForceNew:    true,
+		Description: "Target namespace. (requires Enterprise)",
+	}
+
+	s[fieldVaultToken] = &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Sensitive:   true,
+		Description: "Token to use to provision this resource, in place of the provider's own token.",
+	}
+
+	return s
+}
+
+// resourceDataClientConfig adapts a *schema.ResourceData to
+// ClientConfigProvider by reading back the namespace/vault_token overrides
+// injected by AddPerResourceAuthSchema, if either was set.
+type resourceDataClientConfig struct {
+	d *schema.ResourceData
+}
+
+func (r resourceDataClientConfig) ClientConfig() (ClientConfig, bool) {
+	ns, hasNS := r.d.GetOk(consts.FieldNamespace)
+	token, hasToken := r.d.GetOk(fieldVaultToken)
+	if !hasNS && !hasToken {
+		return ClientConfig{}, false
+	}
+
+	var cfg ClientConfig
+	if hasNS {
+		cfg.Namespace = ns.(string)
+	}
+	if hasToken {
+		cfg.Token = token.(string)
+	}
+
+	return cfg, true
+}
+
+// GetClientWithTokenValidation behaves like GetClient, but for a resource
+// whose schema was extended with AddPerResourceAuthSchema: it derives a
+// client scoped to that resource's namespace/vault_token overrides, and,
+// the first time a given override is used, validates the token via
+// LookupSelf. Validation failures are returned as diag.Diagnostics with
+// the resource's ID attached, rather than a bare error.
+func GetClientWithTokenValidation(d *schema.ResourceData, meta interface{}) (*api.Client, diag.Diagnostics) {
+	p, ok := meta.(*ProviderMeta)
+	if !ok {
+		return nil, diag.Errorf("meta argument must be a %T, not %T", p, meta)
+	}
+
+	cfg, hasOverride := resourceDataClientConfig{d: d}.ClientConfig()
+	if !hasOverride {
+		return GetClientDiag(d, meta)
+	}
+
+	c, err := p.GetClientForConfig(cfg)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	if cfg.Token != "" {
+		key, err := cfg.cacheKey()
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+
+		if err := p.validateTokenOnce(key, c); err != nil {
+			return nil, diag.Diagnostics{
+				{
+					Severity: diag.Error,
+					Summary:  "invalid vault_token override",
+					Detail:   fmt.Sprintf("failed to validate vault_token for %s: %s", d.Id(), err),
+				},
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// EvictClientForResource evicts the cached client derived for d's
+// namespace/vault_token override, if it has one. Resources whose Delete
+// derives a client via GetClientWithTokenValidation should call this from
+// Delete too, so the cached client (and any token it holds) isn't kept
+// alive in ProviderMeta.clientCache for the remainder of the provider
+// process once the resource that created it is gone.
+func EvictClientForResource(d *schema.ResourceData, meta interface{}) error {
+	p, ok := meta.(*ProviderMeta)
+	if !ok {
+		return fmt.Errorf("meta argument must be a %T, not %T", p, meta)
+	}
+
+	cfg, hasOverride := resourceDataClientConfig{d: d}.ClientConfig()
+	if !hasOverride {
+		return nil
+	}
+
+	return p.EvictClientForConfig(cfg)
+}
+
+// validateTokenOnce calls LookupSelf against c the first time key is seen,
+// caching the result so that repeated calls during the same apply don't
+// re-validate an already-confirmed token.
+func (p *ProviderMeta) validateTokenOnce(key string, c *api.Client) error {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	if p.validatedTokens == nil {
+		p.validatedTokens = make(map[string]bool)
+	}
+
+	if p.validatedTokens[key] {
+		return nil
+	}
+
+	if _, err := c.Auth().Token().LookupSelf(); err != nil {
+		return err
+	}
+
+	p.validatedTokens[key] = true
+
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/hashicorp/terraform-provider-vault/internal/consts"
+)
+
+func testPerResourceAuthSchema(t *testing.T) map[string]*schema.Schema {
+	t.Helper()
+	return AddPerResourceAuthSchema(map[string]*schema.Schema{
+		"name": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+	})
+}
+
+func TestResourceDataClientConfig_noOverride(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, testPerResourceAuthSchema(t), map[string]interface{}{
+		"name": "test",
+	})
+
+	_, ok := (resourceDataClientConfig{d: d}).ClientConfig()
+	if ok {
+		t.Fatalf("expected no ClientConfig override when neither namespace nor vault_token is set")
+	}
+}
+
+func TestResourceDataClientConfig_tokenOverride(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, testPerResourceAuthSchema(t), map[string]interface{}{
+		"name":          "test",
+		fieldVaultToken: "s.abcd1234",
+	})
+
+	cfg, ok := (resourceDataClientConfig{d: d}).ClientConfig()
+	if !ok {
+		t.Fatalf("expected a ClientConfig override when vault_token is set")
+	}
+	if cfg.Token != "s.abcd1234" {
+		t.Fatalf("expected Token to be s.abcd1234, got %q", cfg.Token)
+	}
+	if cfg.Namespace != "" {
+		t.Fatalf("expected empty Namespace, got %q", cfg.Namespace)
+	}
+}
+
+func TestResourceDataClientConfig_namespaceOverride(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, testPerResourceAuthSchema(t), map[string]interface{}{
+		"name":                "test",
+		consts.FieldNamespace: "tenant-a",
+	})
+
+	cfg, ok := (resourceDataClientConfig{d: d}).ClientConfig()
+	if !ok {
+		t.Fatalf("expected a ClientConfig override when namespace is set")
+	}
+	if cfg.Namespace != "tenant-a" {
+		t.Fatalf("expected Namespace to be tenant-a, got %q", cfg.Namespace)
+	}
+}
+
+// TestEvictClientForResource verifies that a resource whose vault_token
+// override produced a cached client has that client evicted on Delete, so
+// it isn't kept alive in ProviderMeta.clientCache for the rest of the
+// provider process.
+func TestEvictClientForResource(t *testing.T) {
+	p := testProviderMeta(t)
+
+	d := schema.TestResourceDataRaw(t, testPerResourceAuthSchema(t), map[string]interface{}{
+		"name":          "test",
+		fieldVaultToken: "s.abcd1234",
+	})
+
+	cfg, ok := (resourceDataClientConfig{d: d}).ClientConfig()
+	if !ok {
+		t.Fatalf("expected a ClientConfig override when vault_token is set")
+	}
+
+	if _, err := p.GetClientForConfig(cfg); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := EvictClientForResource(d, p); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	key, err := cfg.cacheKey()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, ok := p.clientCache[key]; ok {
+		t.Fatalf("expected cached client for resource's vault_token override to be evicted")
+	}
+}
+
+// TestEvictClientForResource_noOverride verifies that calling
+// EvictClientForResource for a resource with no namespace/vault_token
+// override is a no-op, rather than erroring.
+func TestEvictClientForResource_noOverride(t *testing.T) {
+	p := testProviderMeta(t)
+
+	d := schema.TestResourceDataRaw(t, testPerResourceAuthSchema(t), map[string]interface{}{
+		"name": "test",
+	})
+
+	if err := EvictClientForResource(d, p); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
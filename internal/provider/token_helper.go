@@ -0,0 +1,39 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// EnvVarVaultConfigPath overrides the default location of the Vault CLI
+	// config file that stores the configured token_helper.
+	EnvVarVaultConfigPath = "VAULT_CONFIG_PATH"
+
+	// EnvVarVaultTokenPath overrides the default location of the on-disk
+	// token cache written by `vault login`.
+	EnvVarVaultTokenPath = "VAULT_TOKEN_PATH"
+)
+
+// TokenHelperError wraps a non-zero exit from the configured Vault token
+// helper, capturing anything the helper wrote to stderr so that callers get
+// more than just a bare "exit status 1".
+type TokenHelperError struct {
+	Err    error
+	Stderr string
+}
+
+func (e *TokenHelperError) Error() string {
+	stderr := strings.TrimSpace(e.Stderr)
+	if stderr == "" {
+		return fmt.Sprintf("token helper error: %s", e.Err)
+	}
+	return fmt.Sprintf("token helper error: %s: %s", e.Err, stderr)
+}
+
+func (e *TokenHelperError) Unwrap() error {
+	return e.Err
+}
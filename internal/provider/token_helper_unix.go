@@ -0,0 +1,62 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !windows
+// +build !windows
+
+package provider
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// tokenConfigPath resolves the path to the Vault CLI config file, honoring
+// VAULT_CONFIG_PATH and falling back to ~/.vault.
+func tokenConfigPath() (string, error) {
+	if p := os.Getenv(EnvVarVaultConfigPath); p != "" {
+		return homedir.Expand(p)
+	}
+	return homedir.Expand("~/.vault")
+}
+
+// tokenFilePath resolves the path to the on-disk token cache, honoring
+// VAULT_TOKEN_PATH and falling back to ~/.vault-token.
+func tokenFilePath() (string, error) {
+	if p := os.Getenv(EnvVarVaultTokenPath); p != "" {
+		return homedir.Expand(p)
+	}
+	return homedir.Expand("~/.vault-token")
+}
+
+// runTokenHelper invokes the configured token helper directly, passing
+// "get" as its sole argument. Executing it directly, rather than via
+// `sh -c "<path> get"`, avoids re-interpreting shell metacharacters that
+// may appear in the helper's path.
+func runTokenHelper(tokenHelperPath string) (string, error) {
+	path, err := homedir.Expand(tokenHelperPath)
+	if err != nil {
+		return "", err
+	}
+
+	if !filepath.IsAbs(path) {
+		path, err = filepath.Abs(path)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(path, "get")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", &TokenHelperError{Err: err, Stderr: stderr.String()}
+	}
+
+	return stdout.String(), nil
+}
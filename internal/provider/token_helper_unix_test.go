@@ -0,0 +1,104 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build !windows
+// +build !windows
+
+package provider
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFakeTokenHelper writes an executable shell script at dir/name that
+// prints token to stdout and exits 0, or, if token is "", prints err to
+// stderr and exits 1. It returns the script's path.
+func writeFakeTokenHelper(t *testing.T, dir, name, token, err string) string {
+	t.Helper()
+
+	script := "#!/bin/sh\n"
+	if token != "" {
+		script += "printf '%s' '" + token + "'\n"
+	} else {
+		script += "printf '%s' '" + err + "' >&2\nexit 1\n"
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write fake token helper: %s", err)
+	}
+
+	return path
+}
+
+// TestRunTokenHelper verifies the happy path: a helper that exits 0 has its
+// stdout returned verbatim.
+func TestRunTokenHelper(t *testing.T) {
+	path := writeFakeTokenHelper(t, t.TempDir(), "helper.sh", "s.abcd1234", "")
+
+	out, err := runTokenHelper(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if out != "s.abcd1234" {
+		t.Fatalf("expected s.abcd1234, got %q", out)
+	}
+}
+
+// TestRunTokenHelper_nonZeroExit verifies that a helper exiting non-zero
+// returns a *TokenHelperError wrapping both the exec error and whatever the
+// helper wrote to stderr, rather than a bare exit-status error.
+func TestRunTokenHelper_nonZeroExit(t *testing.T) {
+	path := writeFakeTokenHelper(t, t.TempDir(), "helper.sh", "", "permission denied")
+
+	_, err := runTokenHelper(path)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	var helperErr *TokenHelperError
+	if !errors.As(err, &helperErr) {
+		t.Fatalf("expected a *TokenHelperError, got %T: %s", err, err)
+	}
+	if helperErr.Stderr != "permission denied" {
+		t.Fatalf("expected stderr to be captured, got %q", helperErr.Stderr)
+	}
+	if helperErr.Err == nil {
+		t.Fatalf("expected the underlying exec error to be set")
+	}
+}
+
+// TestTokenConfigPath verifies that tokenConfigPath honors
+// VAULT_CONFIG_PATH when set.
+func TestTokenConfigPath(t *testing.T) {
+	dir := t.TempDir()
+	want := filepath.Join(dir, "vault-config")
+	t.Setenv(EnvVarVaultConfigPath, want)
+
+	got, err := tokenConfigPath()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+// TestTokenFilePath verifies that tokenFilePath honors VAULT_TOKEN_PATH
+// when set.
+func TestTokenFilePath(t *testing.T) {
+	dir := t.TempDir()
+	want := filepath.Join(dir, "vault-token")
+	t.Setenv(EnvVarVaultTokenPath, want)
+
+	got, err := tokenFilePath()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build windows
+// +build windows
+
+package provider
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// tokenConfigPath resolves the path to the Vault CLI config file on
+// Windows, honoring VAULT_CONFIG_PATH and falling back to
+// %USERPROFILE%\.vault.
+func tokenConfigPath() (string, error) {
+	if p := os.Getenv(EnvVarVaultConfigPath); p != "" {
+		return p, nil
+	}
+	return filepath.Join(os.Getenv("USERPROFILE"), ".vault"), nil
+}
+
+// tokenFilePath resolves the path to the on-disk token cache on Windows,
+// honoring VAULT_TOKEN_PATH and falling back to %USERPROFILE%\.vault-token.
+func tokenFilePath() (string, error) {
+	if p := os.Getenv(EnvVarVaultTokenPath); p != "" {
+		return p, nil
+	}
+	return filepath.Join(os.Getenv("USERPROFILE"), ".vault-token"), nil
+}
+
+// runTokenHelper invokes the configured token helper. A helper ending in
+// ".exe" is executed directly; anything else (a batch file, a shell
+// script under WSL, etc.) is invoked through cmd.exe /C so that Windows'
+// own command interpreter resolves it.
+func runTokenHelper(tokenHelperPath string) (string, error) {
+	path := tokenHelperPath
+	if !filepath.IsAbs(path) {
+		var err error
+		path, err = filepath.Abs(path)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var cmd *exec.Cmd
+	if strings.EqualFold(filepath.Ext(path), ".exe") {
+		cmd = exec.Command(path, "get")
+	} else {
+		cmd = exec.Command("cmd.exe", "/C", path, "get")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", &TokenHelperError{Err: err, Stderr: stderr.String()}
+	}
+
+	return stdout.String(), nil
+}
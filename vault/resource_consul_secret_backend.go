@@ -0,0 +1,393 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/vault/api"
+
+	"github.com/hashicorp/terraform-provider-vault/internal/consts"
+	"github.com/hashicorp/terraform-provider-vault/internal/provider"
+)
+
+func consulSecretBackendResource() *schema.Resource {
+	return &schema.Resource{
+		Create: consulSecretBackendCreate,
+		Read:   consulSecretBackendRead,
+		Update: consulSecretBackendUpdate,
+		Delete: consulSecretBackendDelete,
+		Exists: consulSecretBackendExists,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			consts.FieldPath: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Unique name of the Vault mount to configure.",
+				StateFunc: func(v interface{}) string {
+					return strings.Trim(v.(string), "/")
+				},
+			},
+			consts.FieldDescription: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Human-friendly description of the mount for the backend.",
+			},
+			"default_lease_ttl_seconds": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "Default lease duration for secrets in seconds.",
+			},
+			"max_lease_ttl_seconds": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Computed:    true,
+				ForceNew:    true,
+				Description: "Maximum possible lease duration for secrets in seconds.",
+			},
+			consts.FieldLocal: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Mark the secrets engine as local-only. Local engines are not replicated or removed by replication.",
+			},
+			"address": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: `Specifies the address of the Consul instance, provided as "host:port" like "127.0.0.1:8500".`,
+			},
+			"scheme": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "http",
+				Description: "Specifies the URL scheme to use.",
+			},
+			"token": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Specifies the Consul token to use when managing or issuing credentials.",
+			},
+			"bootstrap": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Performs a one-time bootstrap of the Consul ACL system in new clusters.",
+			},
+			"ca_cert": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "CA certificate to use when verifying the Consul server certificate, in PEM format.",
+			},
+			"client_cert": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Client certificate used for Consul's TLS communication, in PEM format.",
+			},
+			"client_key": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Client key used for Consul's TLS communication, in PEM format.",
+			},
+			"disable_remount": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If set, opts out of mount migration on path updates.",
+			},
+			"partition": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The admin partition that the Consul secrets engine should target. Requires Consul >= 1.11 Enterprise.",
+			},
+			"consul_namespace": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The Consul namespace that the Consul secrets engine should target. Requires Consul >= 1.11 Enterprise.",
+			},
+			"manage_bootstrap_token": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"token", "bootstrap"},
+				Description: "If set, performs the initial Consul ACL bootstrap and manages the resulting " +
+					"management token on the backend's behalf. The raw token is never stored in state; only " +
+					"its accessor is exposed, via token_accessor. Conflicts with token and bootstrap.",
+			},
+			"rotate_token_trigger": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Description: "An arbitrary value which, when changed, rotates the management token of a " +
+					"manage_bootstrap_token-enabled backend in place (clone + revoke), without unmounting it.",
+			},
+			"rotation_token": {
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+				Description: "The backend's current Consul management token, used to authorize rotating it " +
+					"or updating its connection settings when this provider process didn't bootstrap or last " +
+					"rotate it itself (e.g. a later terraform apply in a fresh process). Not required on the " +
+					"same apply that bootstraps or rotates the token.",
+			},
+			"token_accessor": {
+				Type:     schema.TypeString,
+				Computed: true,
+				Description: "Accessor of the Consul ACL token currently configured on the backend, when " +
+					"manage_bootstrap_token is enabled.",
+			},
+		},
+	}
+}
+
+func consulSecretBackendCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := provider.GetClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := d.Get(consts.FieldPath).(string)
+
+	log.Printf("[DEBUG] Mounting Consul secrets backend at %q", path)
+	if err := client.Sys().Mount(path, &api.MountInput{
+		Type:        consts.MountTypeConsul,
+		Description: d.Get(consts.FieldDescription).(string),
+		Config: api.MountConfigInput{
+			DefaultLeaseTTL: fmt.Sprintf("%ds", d.Get("default_lease_ttl_seconds").(int)),
+			MaxLeaseTTL:     fmt.Sprintf("%ds", d.Get("max_lease_ttl_seconds").(int)),
+		},
+		Local: d.Get(consts.FieldLocal).(bool),
+	}); err != nil {
+		return fmt.Errorf("error mounting to %q, err=%w", path, err)
+	}
+
+	d.SetId(path)
+
+	if d.Get("manage_bootstrap_token").(bool) {
+		if err := consulSecretBackendBootstrap(d, meta, path); err != nil {
+			return err
+		}
+	} else if err := consulSecretBackendWriteConfig(d, meta, path); err != nil {
+		return err
+	}
+
+	return consulSecretBackendRead(d, meta)
+}
+
+func consulSecretBackendUpdate(d *schema.ResourceData, meta interface{}) error {
+	client, err := provider.GetClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := d.Id()
+
+	if d.HasChange(consts.FieldPath) {
+		newPath := d.Get(consts.FieldPath).(string)
+		if !d.Get("disable_remount").(bool) {
+			log.Printf("[DEBUG] Remounting Consul secrets backend from %q to %q", path, newPath)
+			if err := client.Sys().Remount(path, newPath); err != nil {
+				return fmt.Errorf("error remounting from %q to %q, err=%w", path, newPath, err)
+			}
+		}
+		consulRenameCachedBootstrapToken(path, newPath)
+		path = newPath
+		d.SetId(path)
+	}
+
+	if d.Get("manage_bootstrap_token").(bool) {
+		if d.HasChange("rotate_token_trigger") {
+			if err := consulSecretBackendRotateBootstrapToken(d, meta, path); err != nil {
+				return err
+			}
+		} else if consulSecretBackendAccessConfigChanged(d) {
+			// Vault's config/access endpoint is write-only, so even a change
+			// that has nothing to do with the token (address, scheme, TLS
+			// material, ...) has to resend the backend's current token
+			// alongside it, or the update would otherwise clear it.
+			token, err := consulCurrentBootstrapToken(d, path)
+			if err != nil {
+				return err
+			}
+			if err := consulSecretBackendWriteAccessConfig(d, meta, path, token); err != nil {
+				return err
+			}
+		}
+	} else if err := consulSecretBackendWriteConfig(d, meta, path); err != nil {
+		return err
+	}
+
+	return consulSecretBackendRead(d, meta)
+}
+
+// consulSecretBackendAccessConfigChanged reports whether any of the fields
+// written to a manage_bootstrap_token backend's config/access endpoint
+// (other than rotate_token_trigger, handled separately) changed in this
+// Update.
+func consulSecretBackendAccessConfigChanged(d *schema.ResourceData) bool {
+	for _, k := range []string{"address", "scheme", "ca_cert", "client_cert", "client_key", "partition", "consul_namespace"} {
+		if d.HasChange(k) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// consulSecretBackendWriteConfig writes the backend's config/access using
+// the token field from config. manage_bootstrap_token backends never go
+// through this path; see consulSecretBackendWriteAccessConfig.
+func consulSecretBackendWriteConfig(d *schema.ResourceData, meta interface{}, path string) error {
+	return consulSecretBackendWriteAccessConfig(d, meta, path, d.Get("token").(string))
+}
+
+func consulSecretBackendWriteAccessConfig(d *schema.ResourceData, meta interface{}, path, token string) error {
+	client, err := provider.GetClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{
+		"address": d.Get("address").(string),
+		"scheme":  d.Get("scheme").(string),
+		"token":   token,
+	}
+
+	if v, ok := d.GetOk("ca_cert"); ok {
+		data["ca_cert"] = v.(string)
+	}
+	if v, ok := d.GetOk("client_cert"); ok {
+		data["client_cert"] = v.(string)
+	}
+	if v, ok := d.GetOk("client_key"); ok {
+		data["client_key"] = v.(string)
+	}
+	if v, ok := d.GetOk("partition"); ok {
+		data["partition"] = v.(string)
+	}
+	if v, ok := d.GetOk("consul_namespace"); ok {
+		data["namespace"] = v.(string)
+	}
+	if d.Get("bootstrap").(bool) {
+		data["bootstrap"] = true
+	}
+
+	configPath := strings.Trim(path, "/") + "/config/access"
+	log.Printf("[DEBUG] Writing Consul secrets backend config at %q", configPath)
+	if _, err := client.Logical().Write(configPath, data); err != nil {
+		return fmt.Errorf("error writing Consul config to %q, err=%w", configPath, err)
+	}
+
+	return nil
+}
+
+func consulSecretBackendRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := provider.GetClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := d.Id()
+
+	mount, err := mountutilGetMount(client, path)
+	if err != nil {
+		if isMountNotFoundError(err) {
+			log.Printf("[WARN] Consul secrets backend %q not found, removing from state", path)
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	if err := d.Set(consts.FieldPath, path); err != nil {
+		return err
+	}
+	if err := d.Set(consts.FieldDescription, mount.Description); err != nil {
+		return err
+	}
+	if err := d.Set("default_lease_ttl_seconds", mount.Config.DefaultLeaseTTL); err != nil {
+		return err
+	}
+	if err := d.Set("max_lease_ttl_seconds", mount.Config.MaxLeaseTTL); err != nil {
+		return err
+	}
+	if err := d.Set(consts.FieldLocal, mount.Local); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func consulSecretBackendDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := provider.GetClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := d.Id()
+	log.Printf("[DEBUG] Unmounting Consul secrets backend %q", path)
+	if err := client.Sys().Unmount(path); err != nil {
+		return fmt.Errorf("error unmounting Consul secrets backend %q, err=%w", path, err)
+	}
+
+	consulDeleteCachedBootstrapToken(path)
+
+	return nil
+}
+
+func consulSecretBackendExists(d *schema.ResourceData, meta interface{}) (bool, error) {
+	client, err := provider.GetClient(d, meta)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = mountutilGetMount(client, d.Id())
+	if err != nil {
+		if isMountNotFoundError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// mountutilGetMount returns the api.MountOutput for path, or a
+// *mountNotFoundError if no such mount exists.
+func mountutilGetMount(client *api.Client, path string) (*api.MountOutput, error) {
+	mounts, err := client.Sys().ListMounts()
+	if err != nil {
+		return nil, err
+	}
+
+	mount, ok := mounts[strings.Trim(path, "/")+"/"]
+	if !ok {
+		return nil, &mountNotFoundError{path: path}
+	}
+
+	return mount, nil
+}
+
+type mountNotFoundError struct {
+	path string
+}
+
+func (e *mountNotFoundError) Error() string {
+	return fmt.Sprintf("mount %q not found", e.path)
+}
+
+func isMountNotFoundError(err error) bool {
+	_, ok := err.(*mountNotFoundError)
+	return ok
+}
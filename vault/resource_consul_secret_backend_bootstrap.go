@@ -0,0 +1,255 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// consulBootstrapTokens caches the live Consul management token for each
+// manage_bootstrap_token-enabled backend, keyed by mount path. It is
+// intentionally kept in memory only: the whole point of
+// manage_bootstrap_token is that the raw token never lands in Terraform
+// state, so there's nowhere durable we're willing to put it. A provider
+// process other than the one that bootstrapped (or last rotated) the token
+// finds an empty cache; it then uses rotation_token if that is set, and
+// otherwise returns an error rather than silently failing to rotate. See
+// consulCurrentBootstrapToken.
+var (
+	consulBootstrapTokensMu sync.Mutex
+	consulBootstrapTokens   = map[string]string{}
+)
+
+func consulCachedBootstrapToken(path string) (string, bool) {
+	consulBootstrapTokensMu.Lock()
+	defer consulBootstrapTokensMu.Unlock()
+	token, ok := consulBootstrapTokens[strings.Trim(path, "/")]
+	return token, ok
+}
+
+func consulSetCachedBootstrapToken(path, token string) {
+	consulBootstrapTokensMu.Lock()
+	defer consulBootstrapTokensMu.Unlock()
+	consulBootstrapTokens[strings.Trim(path, "/")] = token
+}
+
+func consulRenameCachedBootstrapToken(oldPath, newPath string) {
+	consulBootstrapTokensMu.Lock()
+	defer consulBootstrapTokensMu.Unlock()
+	oldKey := strings.Trim(oldPath, "/")
+	newKey := strings.Trim(newPath, "/")
+	if token, ok := consulBootstrapTokens[oldKey]; ok {
+		delete(consulBootstrapTokens, oldKey)
+		consulBootstrapTokens[newKey] = token
+	}
+}
+
+func consulDeleteCachedBootstrapToken(path string) {
+	consulBootstrapTokensMu.Lock()
+	defer consulBootstrapTokensMu.Unlock()
+	delete(consulBootstrapTokens, strings.Trim(path, "/"))
+}
+
+// consulCurrentBootstrapToken returns the Consul management token currently
+// configured on path's manage_bootstrap_token backend: the in-process cache,
+// if this provider process bootstrapped or last rotated it, otherwise the
+// rotation_token the user must supply once that cache is gone, e.g. in the
+// fresh provider process a later, separate terraform apply runs in.
+func consulCurrentBootstrapToken(d *schema.ResourceData, path string) (string, error) {
+	if token, ok := consulCachedBootstrapToken(path); ok {
+		return token, nil
+	}
+
+	if v, ok := d.GetOk("rotation_token"); ok {
+		return v.(string), nil
+	}
+
+	return "", fmt.Errorf("no cached bootstrap token for %q and no rotation_token provided; "+
+		"supply the backend's current Consul management token via rotation_token to rotate or "+
+		"update it from a provider process other than the one that bootstrapped or last rotated it",
+		path)
+}
+
+// consulACLToken is the subset of Consul's ACL token response used here.
+type consulACLToken struct {
+	AccessorID string `json:"AccessorID"`
+	SecretID   string `json:"SecretID"`
+}
+
+// consulACLClient issues the raw HTTP calls to Consul's ACL API needed to
+// bootstrap and rotate the management token that backs a
+// manage_bootstrap_token-enabled vault_consul_secret_backend. The Consul
+// secrets engine's own config/access endpoint is write-only, so none of this
+// can be done through Vault; it has to talk to Consul directly.
+type consulACLClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func newConsulACLClient(d *schema.ResourceData) (*consulACLClient, error) {
+	scheme := d.Get("scheme").(string)
+	address := d.Get("address").(string)
+
+	httpClient := http.DefaultClient
+	if scheme == "https" {
+		tlsConfig := &tls.Config{}
+
+		if v, ok := d.GetOk("ca_cert"); ok {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM([]byte(v.(string))) {
+				return nil, fmt.Errorf("failed to parse ca_cert as PEM")
+			}
+			tlsConfig.RootCAs = pool
+		}
+
+		if certPEM, ok := d.GetOk("client_cert"); ok {
+			keyPEM := d.Get("client_key").(string)
+			cert, err := tls.X509KeyPair([]byte(certPEM.(string)), []byte(keyPEM))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse client_cert/client_key: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+
+		httpClient = &http.Client{
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		}
+	}
+
+	return &consulACLClient{
+		baseURL:    fmt.Sprintf("%s://%s", scheme, address),
+		httpClient: httpClient,
+	}, nil
+}
+
+func (c *consulACLClient) do(method, path, consulToken string) (*consulACLToken, error) {
+	req, err := http.NewRequest(method, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if consulToken != "" {
+		req.Header.Set("X-Consul-Token", consulToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("consul ACL API %s %s returned %d: %s", method, path, resp.StatusCode, string(body))
+	}
+
+	if len(body) == 0 {
+		return nil, nil
+	}
+
+	var token consulACLToken
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("failed to decode consul ACL API response: %w", err)
+	}
+
+	return &token, nil
+}
+
+// bootstrap performs Consul's one-time ACL bootstrap, returning the
+// resulting management token.
+func (c *consulACLClient) bootstrap() (*consulACLToken, error) {
+	return c.do(http.MethodPut, "/v1/acl/bootstrap", "")
+}
+
+// clone creates a new token with the same policies as accessorID, returning
+// the new token. authToken is an existing management token used to
+// authenticate the call.
+func (c *consulACLClient) clone(authToken, accessorID string) (*consulACLToken, error) {
+	return c.do(http.MethodPut, "/v1/acl/token/"+accessorID+"/clone", authToken)
+}
+
+// revoke deletes the token identified by accessorID. authToken is an
+// existing management token used to authenticate the call.
+func (c *consulACLClient) revoke(authToken, accessorID string) error {
+	_, err := c.do(http.MethodDelete, "/v1/acl/token/"+accessorID, authToken)
+	return err
+}
+
+// consulSecretBackendBootstrap performs the one-time Consul ACL bootstrap
+// for a manage_bootstrap_token-enabled backend, writes the resulting
+// management token into the backend's config/access (never into Terraform
+// state), and records only its accessor in state.
+func consulSecretBackendBootstrap(d *schema.ResourceData, meta interface{}, path string) error {
+	aclClient, err := newConsulACLClient(d)
+	if err != nil {
+		return err
+	}
+
+	token, err := aclClient.bootstrap()
+	if err != nil {
+		return fmt.Errorf("failed to bootstrap Consul ACLs for %q: %w", path, err)
+	}
+
+	if err := consulSecretBackendWriteAccessConfig(d, meta, path, token.SecretID); err != nil {
+		return err
+	}
+
+	consulSetCachedBootstrapToken(path, token.SecretID)
+
+	return d.Set("token_accessor", token.AccessorID)
+}
+
+// consulSecretBackendRotateBootstrapToken clones the backend's current
+// management token, points the backend at the clone, and revokes the
+// original. The new raw token goes only to the backend's config/access and
+// the in-process cache; only its accessor reaches Terraform state.
+func consulSecretBackendRotateBootstrapToken(d *schema.ResourceData, meta interface{}, path string) error {
+	currentToken, err := consulCurrentBootstrapToken(d, path)
+	if err != nil {
+		return err
+	}
+
+	oldAccessor := d.Get("token_accessor").(string)
+
+	aclClient, err := newConsulACLClient(d)
+	if err != nil {
+		return err
+	}
+
+	newToken, err := aclClient.clone(currentToken, oldAccessor)
+	if err != nil {
+		return fmt.Errorf("failed to rotate Consul ACL token for %q: %w", path, err)
+	}
+
+	if err := consulSecretBackendWriteAccessConfig(d, meta, path, newToken.SecretID); err != nil {
+		return err
+	}
+
+	// The backend is now live on newToken: record it as current before
+	// attempting the (best-effort) revoke of the old one, so that a
+	// failure below doesn't leave the cache/state pointing at a token
+	// that's already been superseded.
+	consulSetCachedBootstrapToken(path, newToken.SecretID)
+	if err := d.Set("token_accessor", newToken.AccessorID); err != nil {
+		return err
+	}
+
+	if err := aclClient.revoke(newToken.SecretID, oldAccessor); err != nil {
+		return fmt.Errorf("rotated Consul ACL token for %q but failed to revoke the old one: %w", path, err)
+	}
+
+	return nil
+}
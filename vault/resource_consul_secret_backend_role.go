@@ -0,0 +1,358 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/vault/api"
+
+	"github.com/hashicorp/terraform-provider-vault/internal/provider"
+)
+
+func consulSecretBackendRoleResource() *schema.Resource {
+	return &schema.Resource{
+		Create: consulSecretBackendRoleCreate,
+		Read:   consulSecretBackendRoleRead,
+		Update: consulSecretBackendRoleCreate,
+		Delete: consulSecretBackendRoleDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: provider.AddPerResourceAuthSchema(map[string]*schema.Schema{
+			"backend": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The path of the Consul secrets engine backend this role belongs to.",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the Consul secrets engine role.",
+			},
+			"consul_policies": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "List of Consul policies to associate with this role.",
+			},
+			"consul_roles": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "List of Consul roles to attach to the generated token.",
+			},
+			"policies": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The list of Consul ACL policies to associate with these roles.",
+				Deprecated:  "use consul_policies instead",
+			},
+			"token_type": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "client",
+				Description: "Specifies the type of token to create when using this role: 'client' or 'management'.",
+			},
+			"local": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Indicates that the token should not be replicated globally and instead be local to the cluster.",
+			},
+			"ttl": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Computed:    true,
+				Description: "Specifies the TTL for this role.",
+			},
+			"max_ttl": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Computed:    true,
+				Description: "Specifies the max TTL for this role.",
+			},
+			"consul_namespace": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The Consul namespace that the generated token should be scoped to. Requires Consul >= 1.11 Enterprise.",
+			},
+			"partition": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The admin partition that the generated token should be scoped to. Requires Consul >= 1.11 Enterprise.",
+			},
+			"node_identities": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Node identities to attach to the generated token. Requires Consul >= 1.11.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"node_name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The name of the node.",
+						},
+						"datacenter": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The datacenter of the node.",
+						},
+					},
+				},
+			},
+			"service_identities": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Service identities to attach to the generated token. Requires Consul >= 1.11.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"service_name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The name of the service.",
+						},
+						"datacenters": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The datacenters the service identity applies to. An empty list grants access in all datacenters.",
+						},
+					},
+				},
+			},
+		}),
+	}
+}
+
+// consulSecretBackendRoleClient returns a client scoped to d's vault_token
+// override, if any, falling back to the provider's own client otherwise.
+// A role is a natural place to want a narrower-scoped token than the
+// backend it belongs to, e.g. one issued just to whoever owns this role's
+// definition.
+func consulSecretBackendRoleClient(d *schema.ResourceData, meta interface{}) (*api.Client, error) {
+	client, diags := provider.GetClientWithTokenValidation(d, meta)
+	if diags.HasError() {
+		return nil, fmt.Errorf("%s: %s", diags[0].Summary, diags[0].Detail)
+	}
+
+	return client, nil
+}
+
+func consulSecretBackendRolePath(backend, name string) string {
+	return strings.Trim(backend, "/") + "/roles/" + name
+}
+
+func consulSecretBackendRoleCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := consulSecretBackendRoleClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	backend := d.Get("backend").(string)
+	name := d.Get("name").(string)
+	path := consulSecretBackendRolePath(backend, name)
+
+	data := map[string]interface{}{
+		"token_type": d.Get("token_type").(string),
+		"local":      d.Get("local").(bool),
+	}
+
+	for _, k := range []string{"consul_policies", "consul_roles", "policies"} {
+		if v, ok := d.GetOk(k); ok {
+			data[k] = v.([]interface{})
+		}
+	}
+
+	if v, ok := d.GetOk("ttl"); ok {
+		data["ttl"] = v.(int)
+	}
+	if v, ok := d.GetOk("max_ttl"); ok {
+		data["max_ttl"] = v.(int)
+	}
+	if v, ok := d.GetOk("consul_namespace"); ok {
+		data["consul_namespace"] = v.(string)
+	}
+	if v, ok := d.GetOk("partition"); ok {
+		data["partition"] = v.(string)
+	}
+
+	if v, ok := d.GetOk("node_identities"); ok {
+		var identities []string
+		for _, raw := range v.([]interface{}) {
+			m := raw.(map[string]interface{})
+			identities = append(identities, fmt.Sprintf("%s:%s", m["node_name"], m["datacenter"]))
+		}
+		data["node_identities"] = identities
+	}
+
+	if v, ok := d.GetOk("service_identities"); ok {
+		var identities []string
+		for _, raw := range v.([]interface{}) {
+			m := raw.(map[string]interface{})
+			dcs := make([]string, 0)
+			for _, dc := range m["datacenters"].([]interface{}) {
+				dcs = append(dcs, dc.(string))
+			}
+			identity := m["service_name"].(string)
+			if len(dcs) > 0 {
+				identity = fmt.Sprintf("%s:%s", identity, strings.Join(dcs, ","))
+			}
+			identities = append(identities, identity)
+		}
+		data["service_identities"] = identities
+	}
+
+	log.Printf("[DEBUG] Writing Consul secrets backend role %q", path)
+	if _, err := client.Logical().Write(path, data); err != nil {
+		return fmt.Errorf("error writing Consul secrets backend role %q, err=%w", path, err)
+	}
+
+	d.SetId(path)
+
+	return consulSecretBackendRoleRead(d, meta)
+}
+
+func consulSecretBackendRoleRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := consulSecretBackendRoleClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := d.Id()
+
+	secret, err := client.Logical().Read(path)
+	if err != nil {
+		return fmt.Errorf("error reading Consul secrets backend role %q, err=%w", path, err)
+	}
+	if secret == nil {
+		log.Printf("[WARN] Consul secrets backend role %q not found, removing from state", path)
+		d.SetId("")
+		return nil
+	}
+
+	for _, k := range []string{"token_type", "local", "ttl", "max_ttl", "consul_namespace", "partition",
+		"consul_policies", "consul_roles", "policies"} {
+		if v, ok := secret.Data[k]; ok {
+			if err := d.Set(k, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	if v, ok := secret.Data["node_identities"]; ok {
+		identities, err := flattenConsulNodeIdentities(v)
+		if err != nil {
+			return fmt.Errorf("error reading node_identities for Consul secrets backend role %q, err=%w", path, err)
+		}
+		if err := d.Set("node_identities", identities); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := secret.Data["service_identities"]; ok {
+		identities, err := flattenConsulServiceIdentities(v)
+		if err != nil {
+			return fmt.Errorf("error reading service_identities for Consul secrets backend role %q, err=%w", path, err)
+		}
+		if err := d.Set("service_identities", identities); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// flattenConsulNodeIdentities translates Vault's "node:datacenter"-shaped
+// node_identities strings (the same shape consulSecretBackendRoleCreate
+// writes) back into the nested node_name/datacenter objects the schema
+// expects.
+func flattenConsulNodeIdentities(raw interface{}) ([]map[string]interface{}, error) {
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T for node_identities", raw)
+	}
+
+	result := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type %T for node_identities entry", item)
+		}
+
+		parts := strings.SplitN(s, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("unexpected node_identities entry %q, want \"node_name:datacenter\"", s)
+		}
+
+		result = append(result, map[string]interface{}{
+			"node_name":  parts[0],
+			"datacenter": parts[1],
+		})
+	}
+
+	return result, nil
+}
+
+// flattenConsulServiceIdentities translates Vault's
+// "service[:datacenter[,datacenter...]]"-shaped service_identities strings
+// (the same shape consulSecretBackendRoleCreate writes) back into the
+// nested service_name/datacenters objects the schema expects.
+func flattenConsulServiceIdentities(raw interface{}) ([]map[string]interface{}, error) {
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T for service_identities", raw)
+	}
+
+	result := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type %T for service_identities entry", item)
+		}
+
+		serviceName := s
+		var datacenters []string
+		if idx := strings.Index(s, ":"); idx >= 0 {
+			serviceName = s[:idx]
+			for _, dc := range strings.Split(s[idx+1:], ",") {
+				if dc != "" {
+					datacenters = append(datacenters, dc)
+				}
+			}
+		}
+
+		result = append(result, map[string]interface{}{
+			"service_name": serviceName,
+			"datacenters":  datacenters,
+		})
+	}
+
+	return result, nil
+}
+
+func consulSecretBackendRoleDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := consulSecretBackendRoleClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := d.Id()
+	log.Printf("[DEBUG] Deleting Consul secrets backend role %q", path)
+	if _, err := client.Logical().Delete(path); err != nil {
+		return fmt.Errorf("error deleting Consul secrets backend role %q, err=%w", path, err)
+	}
+
+	if err := provider.EvictClientForResource(d, meta); err != nil {
+		return fmt.Errorf("error evicting cached client for Consul secrets backend role %q, err=%w", path, err)
+	}
+
+	return nil
+}
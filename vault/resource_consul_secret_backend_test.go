@@ -5,6 +5,8 @@ package vault
 
 import (
 	"fmt"
+	"os"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -18,9 +20,21 @@ import (
 	"github.com/hashicorp/terraform-provider-vault/testutil"
 )
 
+// TF_ACC_ENTERPRISE gates acceptance test steps that require a Consul
+// enterprise dev binary (admin partitions and namespaces are Consul
+// enterprise-only features), so that OSS Consul CI runs are unaffected.
+const envVarConsulAccEnterprise = "TF_ACC_ENTERPRISE"
+
+func testConsulSecretBackendEnterprisePreCheck(t *testing.T) {
+	if os.Getenv(envVarConsulAccEnterprise) == "" {
+		t.Skipf("%s must be set to run Consul enterprise acceptance tests", envVarConsulAccEnterprise)
+	}
+}
+
 type testMountStore struct {
-	uuid string
-	path string
+	uuid          string
+	path          string
+	tokenAccessor string
 }
 
 func TestConsulSecretBackend(t *testing.T) {
@@ -52,7 +66,7 @@ func TestConsulSecretBackend(t *testing.T) {
 				),
 			},
 			testutil.GetImportTestStep(resourceName, false, nil,
-				"token", "bootstrap", "ca_cert", "client_cert", "client_key", "disable_remount"),
+				"token", "bootstrap", "ca_cert", "client_cert", "client_key", "disable_remount", "partition", "consul_namespace"),
 			{
 				Config: testConsulSecretBackend_initialConfigLocal(path, token),
 				Check: resource.ComposeTestCheckFunc(
@@ -70,7 +84,7 @@ func TestConsulSecretBackend(t *testing.T) {
 				),
 			},
 			testutil.GetImportTestStep(resourceName, false, nil,
-				"token", "bootstrap", "ca_cert", "client_cert", "client_key", "disable_remount"),
+				"token", "bootstrap", "ca_cert", "client_cert", "client_key", "disable_remount", "partition", "consul_namespace"),
 			{
 				Config: testConsulSecretBackend_updateConfig(path, token),
 				Check: resource.ComposeTestCheckFunc(
@@ -88,7 +102,7 @@ func TestConsulSecretBackend(t *testing.T) {
 				),
 			},
 			testutil.GetImportTestStep(resourceName, false, nil,
-				"token", "bootstrap", "ca_cert", "client_cert", "client_key", "disable_remount"),
+				"token", "bootstrap", "ca_cert", "client_cert", "client_key", "disable_remount", "partition", "consul_namespace"),
 			{
 				Config: testConsulSecretBackend_updateConfig_addCerts(path, token),
 				Check: resource.ComposeTestCheckFunc(
@@ -106,7 +120,7 @@ func TestConsulSecretBackend(t *testing.T) {
 				),
 			},
 			testutil.GetImportTestStep(resourceName, false, nil,
-				"token", "bootstrap", "ca_cert", "client_cert", "client_key", "disable_remount"),
+				"token", "bootstrap", "ca_cert", "client_cert", "client_key", "disable_remount", "partition", "consul_namespace"),
 			{
 				Config: testConsulSecretBackend_updateConfig_updateCerts(path, token),
 				Check: resource.ComposeTestCheckFunc(
@@ -124,7 +138,7 @@ func TestConsulSecretBackend(t *testing.T) {
 				),
 			},
 			testutil.GetImportTestStep(resourceName, false, nil,
-				"token", "bootstrap", "ca_cert", "client_cert", "client_key", "disable_remount"),
+				"token", "bootstrap", "ca_cert", "client_cert", "client_key", "disable_remount", "partition", "consul_namespace"),
 		},
 	})
 }
@@ -228,6 +242,127 @@ func testMountCompareUUIDs(path string, store *testMountStore, equal bool) resou
 	}
 }
 
+func testCaptureTokenAccessor(resourceName string, store *testMountStore) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource %s not found in state", resourceName)
+		}
+
+		store.tokenAccessor = rs.Primary.Attributes["token_accessor"]
+		if store.tokenAccessor == "" {
+			return fmt.Errorf("empty token_accessor for resource %s", resourceName)
+		}
+
+		return nil
+	}
+}
+
+func testCompareTokenAccessors(resourceName string, store *testMountStore, equal bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource %s not found in state", resourceName)
+		}
+
+		accessor := rs.Primary.Attributes["token_accessor"]
+		if accessor == "" {
+			return fmt.Errorf("empty token_accessor for resource %s", resourceName)
+		}
+
+		if store.tokenAccessor == accessor {
+			if !equal {
+				return fmt.Errorf("expected token accessor to change after rotation; "+
+					"still equal to %s", store.tokenAccessor)
+			}
+		} else if equal {
+			return fmt.Errorf("expected token accessor to remain the same; "+
+				"got accessor1=%s, accessor2=%s", store.tokenAccessor, accessor)
+		}
+
+		return nil
+	}
+}
+
+// testCheckNoRawBootstrapTokenInState verifies that path's currently cached
+// raw Consul management token doesn't appear as the value of any attribute
+// on any resource in state, i.e. it never made it into what `terraform show
+// -json` would print. Checking the attribute names we expect to be absent
+// (token, bootstrap) only proves ConflictsWith works; this proves the
+// actual secret value itself isn't leaking out under some other name.
+func testCheckNoRawBootstrapTokenInState(path string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rawToken, ok := consulCachedBootstrapToken(path)
+		if !ok {
+			return fmt.Errorf("no cached bootstrap token for %q to check against state", path)
+		}
+
+		for _, mod := range s.Modules {
+			for resName, rs := range mod.Resources {
+				if rs.Primary == nil {
+					continue
+				}
+				for attr, value := range rs.Primary.Attributes {
+					if value == rawToken {
+						return fmt.Errorf("raw bootstrap token for %q found in state at %s.%s", path, resName, attr)
+					}
+				}
+			}
+		}
+
+		return nil
+	}
+}
+
+// TestFlattenConsulNodeIdentities and TestFlattenConsulServiceIdentities
+// guard the Create/Read round trip for node_identities/service_identities:
+// Create flattens these blocks into the plain strings Vault's API expects,
+// so Read has to reverse that exact transformation before calling d.Set,
+// or d.Set fails against the nested schema these fields actually have.
+func TestFlattenConsulNodeIdentities(t *testing.T) {
+	got, err := flattenConsulNodeIdentities([]interface{}{"tf-test-node:dc1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []map[string]interface{}{
+		{"node_name": "tf-test-node", "datacenter": "dc1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestFlattenConsulServiceIdentities(t *testing.T) {
+	for name, tc := range map[string]struct {
+		in   interface{}
+		want []map[string]interface{}
+	}{
+		"with datacenters": {
+			in: []interface{}{"tf-test-service:dc1,dc2"},
+			want: []map[string]interface{}{
+				{"service_name": "tf-test-service", "datacenters": []string{"dc1", "dc2"}},
+			},
+		},
+		"without datacenters": {
+			in: []interface{}{"tf-test-service"},
+			want: []map[string]interface{}{
+				{"service_name": "tf-test-service", "datacenters": []string(nil)},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := flattenConsulServiceIdentities(tc.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
 func testGetMount(path string) (*api.MountOutput, error) {
 	client, err := provider.GetClient("", testProvider.Meta())
 
@@ -366,3 +501,134 @@ resource "vault_consul_secret_backend" "test" {
   client_key = "UPDATED-FAKE-CLIENT-CERT-KEY-MATERIAL"
 }`, path, token)
 }
+
+// TestConsulSecretBackend_enterprise exercises the admin partition and
+// namespace support on vault_consul_secret_backend and
+// vault_consul_secret_backend_role against a Consul enterprise dev binary.
+// It is gated behind TF_ACC_ENTERPRISE since admin partitions and namespaces
+// require Consul >= 1.11 enterprise.
+func TestConsulSecretBackend_enterprise(t *testing.T) {
+	t.Parallel()
+	path := acctest.RandomWithPrefix("tf-test-consul")
+	resourceType := "vault_consul_secret_backend"
+	resourceName := resourceType + ".test"
+	roleResourceName := "vault_consul_secret_backend_role.test"
+	token := "026a0c16-87cd-4c2d-b3f3-fb539f592b7e"
+	partition := "tf-test-partition"
+	namespace := "tf-test-namespace"
+
+	resource.Test(t, resource.TestCase{
+		Providers: testProviders,
+		PreCheck: func() {
+			testutil.TestAccPreCheck(t)
+			testConsulSecretBackendEnterprisePreCheck(t)
+		},
+		CheckDestroy: testCheckMountDestroyed(resourceType, consts.MountTypeConsul, consts.FieldPath),
+		Steps: []resource.TestStep{
+			{
+				Config: testConsulSecretBackend_enterpriseConfig(path, token, partition, namespace),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, consts.FieldPath, path),
+					resource.TestCheckResourceAttr(resourceName, "partition", partition),
+					resource.TestCheckResourceAttr(resourceName, "consul_namespace", namespace),
+					resource.TestCheckResourceAttr(roleResourceName, "consul_namespace", namespace),
+					resource.TestCheckResourceAttr(roleResourceName, "partition", partition),
+					resource.TestCheckResourceAttr(roleResourceName, "node_identities.0.node_name", "tf-test-node"),
+					resource.TestCheckResourceAttr(roleResourceName, "node_identities.0.datacenter", "dc1"),
+					resource.TestCheckResourceAttr(roleResourceName, "service_identities.0.service_name", "tf-test-service"),
+					resource.TestCheckResourceAttr(roleResourceName, "service_identities.0.datacenters.0", "dc1"),
+				),
+			},
+			testutil.GetImportTestStep(resourceName, false, nil,
+				"token", "bootstrap", "ca_cert", "client_cert", "client_key", "disable_remount", "partition", "consul_namespace"),
+		},
+	})
+}
+
+func testConsulSecretBackend_enterpriseConfig(path, token, partition, namespace string) string {
+	return fmt.Sprintf(`
+resource "vault_consul_secret_backend" "test" {
+  path             = "%s"
+  description      = "test description"
+  address          = "127.0.0.1:8500"
+  token            = "%s"
+  partition        = "%s"
+  consul_namespace = "%s"
+}
+
+resource "vault_consul_secret_backend_role" "test" {
+  backend          = vault_consul_secret_backend.test.path
+  name             = "tf-test-role"
+  consul_namespace = "%s"
+  partition        = "%s"
+
+  node_identities {
+    node_name  = "tf-test-node"
+    datacenter = "dc1"
+  }
+
+  service_identities {
+    service_name = "tf-test-service"
+    datacenters  = ["dc1"]
+  }
+}
+`, path, token, partition, namespace, namespace, partition)
+}
+
+// TestConsulSecretBackend_manageBootstrapToken verifies that the
+// manage_bootstrap_token rotation mode never surfaces the raw bootstrap
+// token in any attribute of Terraform state, and that bumping
+// rotate_token_trigger rotates the backend's root token in place, without
+// unmounting the backend.
+func TestConsulSecretBackend_manageBootstrapToken(t *testing.T) {
+	t.Parallel()
+	path := acctest.RandomWithPrefix("tf-test-consul")
+	addr := "127.0.0.1:8500"
+	resourceType := "vault_consul_secret_backend"
+	resourceName := resourceType + ".test"
+
+	store := &testMountStore{}
+
+	resource.Test(t, resource.TestCase{
+		Providers:    testProviders,
+		PreCheck:     func() { testutil.TestAccPreCheck(t) },
+		CheckDestroy: testCheckMountDestroyed(resourceType, consts.MountTypeConsul, consts.FieldPath),
+		Steps: []resource.TestStep{
+			{
+				Config: testConsulSecretBackend_manageBootstrapTokenConfig(path, addr, "initial"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, consts.FieldPath, path),
+					resource.TestCheckResourceAttr(resourceName, "manage_bootstrap_token", "true"),
+					resource.TestCheckResourceAttr(resourceName, "rotate_token_trigger", "initial"),
+					resource.TestCheckNoResourceAttr(resourceName, "bootstrap"),
+					resource.TestCheckNoResourceAttr(resourceName, "token"),
+					testCaptureMountUUID(path, store),
+					testCaptureTokenAccessor(resourceName, store),
+					testCheckNoRawBootstrapTokenInState(path),
+				),
+			},
+			{
+				Config: testConsulSecretBackend_manageBootstrapTokenConfig(path, addr, "rotated"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "rotate_token_trigger", "rotated"),
+					testCheckNoRawBootstrapTokenInState(path),
+					testMountCompareUUIDs(path, store, true),
+					testCompareTokenAccessors(resourceName, store, false),
+				),
+			},
+		},
+	})
+}
+
+func testConsulSecretBackend_manageBootstrapTokenConfig(path, addr, rotateTrigger string) string {
+	return fmt.Sprintf(`
+resource "vault_consul_secret_backend" "test" {
+  path                   = "%s"
+  description            = "test description"
+  address                = "%s"
+  manage_bootstrap_token = true
+  rotate_token_trigger   = "%s"
+  disable_remount        = true
+}
+`, path, addr, rotateTrigger)
+}
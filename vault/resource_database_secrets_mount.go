@@ -0,0 +1,293 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package vault
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/vault/api"
+
+	"github.com/hashicorp/terraform-provider-vault/internal/consts"
+	"github.com/hashicorp/terraform-provider-vault/internal/provider"
+)
+
+// databasePluginFields lists the fields common to every database
+// connection block (mssql, cassandra, ...): the ones that aren't part of
+// the plugin's own connection_details.
+var databasePluginFields = map[string]bool{
+	"name":                     true,
+	"plugin_name":              true,
+	"allowed_roles":            true,
+	"verify_connection":        true,
+	"root_rotation_statements": true,
+}
+
+func mssqlConnectionResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name":                     {Type: schema.TypeString, Required: true, Description: "Name of the database connection."},
+			"plugin_name":              {Type: schema.TypeString, Optional: true, Default: "mssql-database-plugin", Description: "Name of the database plugin."},
+			"connection_url":           {Type: schema.TypeString, Required: true, Sensitive: true, Description: "Connection string used to connect to the database."},
+			"username":                 {Type: schema.TypeString, Optional: true, Description: "Username for the database."},
+			"password":                 {Type: schema.TypeString, Optional: true, Sensitive: true, Description: "Password for the database."},
+			"allowed_roles":            {Type: schema.TypeList, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}, Description: "Roles allowed to use this connection."},
+			"verify_connection":        {Type: schema.TypeBool, Optional: true, Default: true, Description: "Whether to verify the connection during configuration."},
+			"root_rotation_statements": {Type: schema.TypeList, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}, Description: "Statements to rotate the root user's password."},
+		},
+	}
+}
+
+func cassandraConnectionResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name":                     {Type: schema.TypeString, Required: true, Description: "Name of the database connection."},
+			"plugin_name":              {Type: schema.TypeString, Optional: true, Default: "cassandra-database-plugin", Description: "Name of the database plugin."},
+			"hosts":                    {Type: schema.TypeList, Required: true, Elem: &schema.Schema{Type: schema.TypeString}, Description: "Cassandra hosts to connect to."},
+			"port":                     {Type: schema.TypeInt, Optional: true, Default: 9042, Description: "Transport port to use."},
+			"username":                 {Type: schema.TypeString, Optional: true, Description: "Username to connect with."},
+			"password":                 {Type: schema.TypeString, Optional: true, Sensitive: true, Description: "Password to connect with."},
+			"tls":                      {Type: schema.TypeBool, Optional: true, Description: "Whether to use TLS when connecting."},
+			"insecure_tls":             {Type: schema.TypeBool, Optional: true, Description: "Whether to skip verification of the server certificate when using TLS."},
+			"pem_bundle":               {Type: schema.TypeString, Optional: true, Sensitive: true, Description: "PEM-format CA/client certificate/key bundle."},
+			"pem_json":                 {Type: schema.TypeString, Optional: true, Sensitive: true, Description: "JSON-format CA/client certificate/key bundle."},
+			"protocol_version":         {Type: schema.TypeInt, Optional: true, Default: 4, Description: "CQL protocol version to use."},
+			"connect_timeout":          {Type: schema.TypeInt, Optional: true, Default: 5, Description: "Timeout, in seconds, for connection attempts."},
+			"local_datacenter":         {Type: schema.TypeString, Optional: true, Description: "Name of the local datacenter, used by the DC-aware load balancing policy."},
+			"socket_keep_alive":        {Type: schema.TypeInt, Optional: true, Default: 0, Description: "Keep-alive timeout, in seconds, for the socket."},
+			"consistency":              {Type: schema.TypeString, Optional: true, Description: "Consistency level to use, e.g. Quorum."},
+			"username_template":        {Type: schema.TypeString, Optional: true, Description: "Template used to generate usernames for dynamic credentials."},
+			"allowed_roles":            {Type: schema.TypeList, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}, Description: "Roles allowed to use this connection."},
+			"root_rotation_statements": {Type: schema.TypeList, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}, Description: "Statements to rotate the root user's password."},
+			"verify_connection":        {Type: schema.TypeBool, Optional: true, Default: true, Description: "Whether to verify the connection during configuration."},
+		},
+	}
+}
+
+func databaseSecretsMountResource() *schema.Resource {
+	return &schema.Resource{
+		Create: databaseSecretsMountCreate,
+		Update: databaseSecretsMountUpdate,
+		Read:   databaseSecretsMountRead,
+		Delete: databaseSecretsMountDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			consts.FieldPath: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Path where the database secrets engine is mounted.",
+			},
+			consts.FieldDescription: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Human-friendly description of the mount for the backend.",
+			},
+			"mssql": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        mssqlConnectionResource(),
+				Description: "A connection to a MSSQL database.",
+			},
+			"cassandra": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        cassandraConnectionResource(),
+				Description: "A connection to a Cassandra database.",
+			},
+		},
+	}
+}
+
+// buildConnectionData translates a single mssql/cassandra block (raw, as
+// read off the schema.ResourceData) into the payload expected by Vault's
+// database/config/<name> endpoint: the plugin-level fields at the top, and
+// everything else nested under connection_details.
+func buildConnectionData(raw map[string]interface{}) (string, map[string]interface{}) {
+	name := raw["name"].(string)
+
+	data := map[string]interface{}{
+		"plugin_name": raw["plugin_name"],
+	}
+	if v, ok := raw["allowed_roles"]; ok {
+		data["allowed_roles"] = v
+	}
+	if v, ok := raw["verify_connection"]; ok {
+		data["verify_connection"] = v
+	}
+	if v, ok := raw["root_rotation_statements"]; ok {
+		data["root_rotation_statements"] = v
+	}
+
+	connectionDetails := map[string]interface{}{}
+	for k, v := range raw {
+		if databasePluginFields[k] {
+			continue
+		}
+		connectionDetails[k] = v
+	}
+	data["connection_details"] = connectionDetails
+
+	return name, data
+}
+
+func writeConnections(d *schema.ResourceData, meta interface{}, path, field string) error {
+	client, err := provider.GetClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	blocks, ok := d.GetOk(field)
+	if !ok {
+		return nil
+	}
+
+	for _, raw := range blocks.([]interface{}) {
+		name, data := buildConnectionData(raw.(map[string]interface{}))
+		connPath := strings.Trim(path, "/") + "/config/" + name
+		log.Printf("[DEBUG] Writing database connection config at %q", connPath)
+		if _, err := client.Logical().Write(connPath, data); err != nil {
+			return fmt.Errorf("error writing database connection %q, err=%w", connPath, err)
+		}
+	}
+
+	return nil
+}
+
+func databaseSecretsMountCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := provider.GetClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := d.Get(consts.FieldPath).(string)
+
+	log.Printf("[DEBUG] Mounting database secrets backend at %q", path)
+	if err := client.Sys().Mount(path, &api.MountInput{
+		Type:        "database",
+		Description: d.Get(consts.FieldDescription).(string),
+	}); err != nil {
+		return fmt.Errorf("error mounting to %q, err=%w", path, err)
+	}
+
+	d.SetId(path)
+
+	for _, field := range []string{"mssql", "cassandra"} {
+		if err := writeConnections(d, meta, path, field); err != nil {
+			return err
+		}
+	}
+
+	return databaseSecretsMountRead(d, meta)
+}
+
+// connectionNamesToDelete returns the connection names present in a
+// mssql/cassandra field's old config but absent from its new one, i.e. the
+// blocks a config change removed and whose backing Vault connection
+// therefore needs to be torn down rather than just left behind.
+func connectionNamesToDelete(old, new []interface{}) []string {
+	newNames := make(map[string]bool, len(new))
+	for _, raw := range new {
+		newNames[raw.(map[string]interface{})["name"].(string)] = true
+	}
+
+	var removed []string
+	for _, raw := range old {
+		name := raw.(map[string]interface{})["name"].(string)
+		if !newNames[name] {
+			removed = append(removed, name)
+		}
+	}
+
+	return removed
+}
+
+// deleteRemovedConnections deletes the Vault config/<name> connection for
+// every name that field's old config had but its new one doesn't, so a
+// mssql/cassandra block removed from a vault_database_secrets_mount
+// resource's config is actually torn down in Vault instead of just
+// disappearing from state while it keeps working against the real backend.
+func deleteRemovedConnections(d *schema.ResourceData, meta interface{}, path, field string) error {
+	client, err := provider.GetClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	old, new := d.GetChange(field)
+	for _, name := range connectionNamesToDelete(old.([]interface{}), new.([]interface{})) {
+		connPath := strings.Trim(path, "/") + "/config/" + name
+		log.Printf("[DEBUG] Deleting database connection config at %q", connPath)
+		if _, err := client.Logical().Delete(connPath); err != nil {
+			return fmt.Errorf("error deleting database connection %q, err=%w", connPath, err)
+		}
+	}
+
+	return nil
+}
+
+func databaseSecretsMountUpdate(d *schema.ResourceData, meta interface{}) error {
+	path := d.Id()
+
+	for _, field := range []string{"mssql", "cassandra"} {
+		if d.HasChange(field) {
+			if err := deleteRemovedConnections(d, meta, path, field); err != nil {
+				return err
+			}
+			if err := writeConnections(d, meta, path, field); err != nil {
+				return err
+			}
+		}
+	}
+
+	return databaseSecretsMountRead(d, meta)
+}
+
+func databaseSecretsMountRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := provider.GetClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := d.Id()
+
+	mount, err := mountutilGetMount(client, path)
+	if err != nil {
+		if isMountNotFoundError(err) {
+			log.Printf("[WARN] database secrets backend %q not found, removing from state", path)
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	if err := d.Set(consts.FieldPath, path); err != nil {
+		return err
+	}
+	if err := d.Set(consts.FieldDescription, mount.Description); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func databaseSecretsMountDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := provider.GetClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	path := d.Id()
+	log.Printf("[DEBUG] Unmounting database secrets backend %q", path)
+	if err := client.Sys().Unmount(path); err != nil {
+		return fmt.Errorf("error unmounting database secrets backend %q, err=%w", path, err)
+	}
+
+	return nil
+}